@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gookit/color"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/baseline"
+)
+
+const (
+	errLoadBaseline  = "failed to load baseline"
+	errWriteBaseline = "failed to write baseline"
+)
+
+// loadBaseline loads the baseline configured by --baseline or returns nil if
+// none is configured.
+func (c *lintPackageCmd) loadBaseline(fs afero.Fs) (*baseline.Baseline, error) {
+	if c.Baseline == "" {
+		return nil, nil
+	}
+	b, err := baseline.Load(fs, c.Baseline)
+	if err != nil {
+		return nil, errors.Wrap(err, errLoadBaseline)
+	}
+	return b, nil
+}
+
+// lint pkg and remove the issues of base from the report. Returns the entries
+// of base that no longer occur.
+func (c *lintPackageCmd) lint(ctx context.Context, pkgLinter lint.Linter, pkg *xpkg.Package, base *baseline.Baseline) (lint.LinterReport, []baseline.Entry, error) {
+	report, err := pkgLinter.Lint(ctx, pkg)
+	runErr := &lint.RunError{}
+	if base == nil || (err != nil && !errors.As(err, &runErr)) {
+		return report, nil, err
+	}
+	// The issues of rules that did run are filtered as well.
+	report, stale := base.Filter(report, c.Package)
+	return report, stale, err
+}
+
+// writeBaseline records the issues of report in the file configured by
+// --write-baseline.
+func (c *lintPackageCmd) writeBaseline(fs afero.Fs, report lint.LinterReport) error {
+	if err := baseline.New(report, c.Package).Write(fs, c.WriteBaseline); err != nil {
+		return errors.Wrap(err, errWriteBaseline)
+	}
+	fmt.Fprintf(os.Stdout, "%d issues written to %s\n", len(report.Issues), c.WriteBaseline)
+	return nil
+}
+
+// printStaleBaseline prints the baseline entries that no longer occur, so they
+// can be removed from the baseline.
+func printStaleBaseline(stale []baseline.Entry) {
+	if len(stale) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stdout, "%d baseline issues no longer occur and can be removed from the baseline:\n", len(stale))
+	for _, e := range stale {
+		location := e.File
+		if e.Path != "" {
+			location += " " + e.Path
+		}
+		fmt.Fprintf(os.Stdout, "  %s [%s] %s\n", color.Gray.Render(e.Fingerprint), e.Rule, e.Description)
+		if location != "" {
+			fmt.Fprintln(os.Stdout, color.Blue.Render(fmt.Sprintf("    in %s", location)))
+		}
+	}
+	fmt.Fprintln(os.Stdout, "")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/go-log/log"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/baseline"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/fix"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	errFmtReadFile  = "failed to read %s"
+	errFmtWriteFile = "failed to write %s"
+	errFmtFixIssue  = "failed to fix issue in %s"
+	errDiffFile     = "failed to diff fixed file"
+)
+
+// fixReport applies the fixes of the issues in report to the files of the
+// package. Fixes of an issue that cannot be applied are skipped. In dry run
+// mode the changes are printed as unified diff instead of being written.
+// Otherwise the package is linted again and the remaining issues are printed.
+// Issues of base are neither fixed nor reported.
+func (c *lintPackageCmd) fixReport(ctx context.Context, fs afero.Fs, report lint.LinterReport, base *baseline.Baseline, logger log.Logger) error {
+	issuesBySource := map[string][]lint.Issue{}
+	for _, issue := range report.Issues {
+		if issue.Entry == nil || len(issue.Fixes) == 0 {
+			continue
+		}
+		issuesBySource[issue.Entry.Source] = append(issuesBySource[issue.Entry.Source], issue)
+	}
+	sources := make([]string, 0, len(issuesBySource))
+	for source := range issuesBySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	fixed := 0
+	for _, source := range sources {
+		n, err := c.fixFile(fs, source, issuesBySource[source], logger)
+		if err != nil {
+			return err
+		}
+		fixed += n
+	}
+
+	if c.DryRun {
+		fmt.Fprintf(os.Stdout, "%d of %d issues can be fixed\n", fixed, len(report.Issues))
+		return nil
+	}
+	fmt.Fprintf(os.Stdout, "%d of %d issues fixed\n", fixed, len(report.Issues))
+	if fixed == 0 {
+		return c.printReport(report)
+	}
+
+	pkg, err := parse.NewPackageDirectoryParser(fs).ParsePackage(c.Package)
+	if err != nil {
+		return errors.Wrap(err, errParsePackage)
+	}
+	_, pkgLinter, err := c.buildLinter(ctx, fs, logger, pkg)
+	if err != nil {
+		return err
+	}
+	report, stale, err := c.lint(ctx, pkgLinter, pkg, base)
+	if err != nil {
+		return errors.Wrap(err, errLintPackage)
+	}
+	printStaleBaseline(stale)
+	return c.printReport(report)
+}
+
+// fixFile applies the fixes of issues to the file at path and returns the
+// number of fixed issues. The fixes of each issue are applied together, so
+// an issue is either fixed completely or not at all.
+func (c *lintPackageCmd) fixFile(fs afero.Fs, path string, issues []lint.Issue, logger log.Logger) (int, error) {
+	raw, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return 0, errors.Wrapf(err, errFmtReadFile, path)
+	}
+	content := raw
+	fixed := 0
+	for _, issue := range issues {
+		res, err := fix.Apply(content, issue.Fixes)
+		if err != nil {
+			logger.Log(errors.Wrapf(err, errFmtFixIssue, path))
+			continue
+		}
+		content = res
+		fixed++
+	}
+	if fixed == 0 {
+		return 0, nil
+	}
+
+	if c.DryRun {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(raw)),
+			B:        difflib.SplitLines(string(content)),
+			FromFile: path,
+			ToFile:   path,
+			Context:  3,
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, errDiffFile)
+		}
+		fmt.Fprint(os.Stdout, diff)
+		return fixed, nil
+	}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, errFmtWriteFile, path)
+	}
+	if err := afero.WriteFile(fs, path, content, info.Mode()); err != nil {
+		return 0, errors.Wrapf(err, errFmtWriteFile, path)
+	}
+	return fixed, nil
+}
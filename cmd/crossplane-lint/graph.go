@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/go-log/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/graph"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	errWriteGraph = "failed to write graph"
+)
+
+type graphCmd struct {
+	Package string `short:"f" help:"Path to the package whose graph should be exported" type:"existingDir" required:"true"`
+	Format  string `enum:"dot,mermaid,json" default:"dot" help:"Output format of the graph (dot, mermaid or json)."`
+
+	packageFlags `embed:""`
+}
+
+func (c *graphCmd) Run(ctx context.Context, fs afero.Fs, logger log.Logger) error {
+	pkg, err := parse.NewPackageDirectoryParser(fs).ParsePackage(c.Package)
+	if err != nil {
+		return errors.Wrap(err, errParsePackage)
+	}
+	config, err := c.getConfig(fs)
+	if err != nil {
+		return errors.Wrap(err, errLoadConfig)
+	}
+	schemaStore, err := c.buildSchemaStore(ctx, fs, logger, config, pkg)
+	if err != nil {
+		return err
+	}
+	g := graph.Build(pkg, schemaStore)
+	return errors.Wrap(g.Write(os.Stdout, c.Format), errWriteGraph)
+}
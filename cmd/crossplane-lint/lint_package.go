@@ -1,16 +1,23 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/go-log/log"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"sigs.k8s.io/yaml"
 
 	"github.com/crossplane-contrib/crossplane-lint/internal/config"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/fetch"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
 	linter "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/print"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
@@ -20,58 +27,118 @@ import (
 const (
 	errParsePackage            = "failed to parse package"
 	errLoadPackageDependencies = "failed to load package dependencies"
+	errLoadAdditionalSchemas   = "failed to load additional schemas"
 	errLintPackage             = "failed to lint package"
-	errLinterIssues            = "%d issues discovered during linting"
+	errLinterIssues            = "%d issues with severity %s or higher discovered during linting"
 	errLoadConfig              = "failed to load config"
 	errRegisterPackageSchema   = "failed to register package schemas"
+	errLoadEnvironmentSchema   = "failed to load environment schema"
+	errLoadKubernetesSchemas   = "failed to load Kubernetes schemas"
+	errFmtKubernetesVersion    = "schemas of Kubernetes %s are not bundled, only %s: configure kubernetes.schemas"
+	errFmtReadOpenAPI          = "failed to read %s"
+	errWatchPackage            = "failed to watch package"
+	errLoadCustomRules         = "failed to load custom rules"
+	errLoadPlugins             = "failed to load plugins"
+	errDryRunWithoutFix        = "--dry-run requires --fix"
+	errFixWithWatch            = "--fix cannot be combined with --watch"
+	errWriteBaselineWithMode   = "--write-baseline cannot be combined with --fix or --watch"
 )
 
-type lintPackageCmd struct {
-	Package string `short:"f" help:"Path to the package that should be linted" type:"existingDir" required:"true"`
+const (
+	outputCodeFrame = "codeframe"
+)
 
+// packageFlags are the flags of commands that lint a package together with
+// the packages configured in the config file.
+type packageFlags struct {
 	Config string `env:"CROSSPLANE-LINT_CONFIG" type:"path" help:"Path to the config file." default:".crossplane-lint.yaml"`
 	Home   string `env:"CROSSPLANE-LINT_HOME" type:"path" help:"Path to the CROSSPLANE-LINT home directy."`
 }
 
-func (c *lintPackageCmd) Run(fs afero.Fs, logger log.Logger) error {
-	parser := parse.NewPackageDirectoryParser(fs)
+type lintPackageCmd struct {
+	Package       string        `short:"f" help:"Path to the package that should be linted" type:"existingDir" required:"true"`
+	Watch         bool          `help:"Watch the package for changes and lint it again after each change."`
+	WatchInterval time.Duration `help:"Interval in which the package is checked for changes in watch mode." default:"1s"`
+	Fix           bool          `help:"Fix issues that have an unambiguous fix in the package files."`
+	DryRun        bool          `help:"Print the changes of --fix as diff instead of writing them."`
+	Output        string        `short:"o" enum:"text,codeframe" default:"text" help:"Output format of issues (text or codeframe)."`
+	FailOn        string        `enum:"error,warning,info" default:"error" help:"Lowest severity of issues that fail the run (error, warning or info)."`
+	Baseline      string        `type:"path" help:"Path to a baseline file. Only issues that are not in the baseline are reported."`
+	WriteBaseline string        `type:"path" help:"Record the discovered issues in a baseline file instead of reporting them."`
 
-	pkg, err := parser.ParsePackage(c.Package)
+	packageFlags `embed:""`
+}
+
+func (c *lintPackageCmd) Run(ctx context.Context, fs afero.Fs, logger log.Logger) error {
+	if c.DryRun && !c.Fix {
+		return errors.New(errDryRunWithoutFix)
+	}
+	if c.Fix && c.Watch {
+		return errors.New(errFixWithWatch)
+	}
+	if c.WriteBaseline != "" && (c.Fix || c.Watch) {
+		return errors.New(errWriteBaselineWithMode)
+	}
+	base, err := c.loadBaseline(fs)
 	if err != nil {
-		return errors.Wrap(err, errParsePackage)
+		return err
 	}
 
-	config, err := c.getConfig(fs)
-	if err != nil {
-		return errors.Wrap(err, errLoadConfig)
+	parser := parse.NewPackageDirectoryParser(fs)
+
+	// Start watching before parsing to not miss changes in between.
+	watcher := parse.NewDirectoryWatcher(fs, c.Package)
+	if c.Watch {
+		if _, err := watcher.Poll(); err != nil {
+			return errors.Wrap(err, errWatchPackage)
+		}
 	}
 
-	imageCacheDir, err := c.getImageCacheDir()
+	pkg, err := parser.ParsePackage(c.Package)
 	if err != nil {
-		return err
+		return errors.Wrap(err, errParsePackage)
 	}
-	fetcher := fetch.NewFsCacheFetcher(
-		afero.NewBasePathFs(fs, imageCacheDir),
-		fetch.NewRemoteFetcher(),
-	)
 
-	pkgDeps, err := parse.LoadPackageDependencies(config.AdditionalPackages, parse.NewPackageImageParser(fetcher))
+	schemaStore, pkgLinter, err := c.buildLinter(ctx, fs, logger, pkg)
 	if err != nil {
-		return errors.Wrap(err, errLoadPackageDependencies)
+		return err
 	}
 
-	schemaStore := schema.NewSchemaStore()
-	if err := schemaStore.RegisterPackage(pkg); err != nil {
-		return errors.Wrap(err, errRegisterPackageSchema)
+	if c.Watch {
+		return c.watch(ctx, watcher, parser, pkg, schemaStore, pkgLinter, base, logger)
 	}
-	for _, dep := range pkgDeps {
-		if err := schemaStore.RegisterPackage(dep); err != nil {
-			return errors.Wrap(err, errRegisterPackageSchema)
+	if c.WriteBaseline != "" {
+		report, err := pkgLinter.Lint(ctx, pkg)
+		if err != nil {
+			return errors.Wrap(err, errLintPackage)
 		}
+		return c.writeBaseline(fs, report)
+	}
+	report, stale, err := c.lint(ctx, pkgLinter, pkg, base)
+	if err != nil {
+		return errors.Wrap(err, errLintPackage)
 	}
+	if c.Fix {
+		return c.fixReport(ctx, fs, report, base, logger)
+	}
+	printStaleBaseline(stale)
+	return c.printReport(report)
+}
 
-	pkgLinter := linter.Newlinter(schemaStore)
-	report := pkgLinter.Lint(pkg)
+// issuesError is returned if issues with a severity at or above the failure
+// threshold were discovered.
+type issuesError struct {
+	count     int
+	threshold lint.Severity
+}
+
+func (e *issuesError) Error() string {
+	return fmt.Sprintf(errLinterIssues, e.count, e.threshold)
+}
+
+// printReport prints the issues of report followed by a summary. Returns an
+// issuesError if there are issues at or above the --fail-on severity.
+func (c *lintPackageCmd) printReport(report lint.LinterReport) error {
 	if len(report.Issues) == 0 {
 		return nil
 	}
@@ -79,14 +146,128 @@ func (c *lintPackageCmd) Run(fs afero.Fs, logger log.Logger) error {
 	if err := printer.PrintReport(report); err != nil {
 		return err
 	}
-	return errors.Errorf(errLinterIssues, len(report.Issues))
+	fmt.Fprintln(os.Stdout, formatSeveritySummary(report))
+
+	threshold := lint.Severity(c.FailOn)
+	failing := 0
+	for _, issue := range report.Issues {
+		if issue.Severity.AtLeast(threshold) {
+			failing++
+		}
+	}
+	if failing == 0 {
+		return nil
+	}
+	return &issuesError{count: failing, threshold: threshold}
+}
+
+// formatSeveritySummary returns the number of issues of report per severity,
+// for example "2 errors, 1 warning, 0 infos".
+func formatSeveritySummary(report lint.LinterReport) string {
+	counts := report.CountBySeverity()
+	parts := make([]string, 0, len(lint.Severities()))
+	for _, sev := range lint.Severities() {
+		n := counts[sev]
+		if n == 1 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, sev))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %ss", n, sev))
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
 func (c *lintPackageCmd) buildPrinter() print.Printer {
+	if c.Output == outputCodeFrame {
+		return print.NewCodeFramePrinter(os.Stdout)
+	}
 	return print.NewTextPrinter(os.Stdout)
 }
 
-func (c *lintPackageCmd) getImageCacheDir() (string, error) {
+// buildLinter loads the config and builds a linter for pkg together with the
+// SchemaStore it uses.
+func (c *packageFlags) buildLinter(ctx context.Context, fs afero.Fs, logger log.Logger, pkg *xpkg.Package) (*schema.SchemaStore, lint.Linter, error) {
+	schemaStore, opts, err := c.buildLinterOptions(ctx, fs, logger, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schemaStore, linter.Newlinter(schemaStore, opts...), nil
+}
+
+// buildLinterOptions loads the config and returns the SchemaStore of pkg and
+// the options of its linter.
+func (c *packageFlags) buildLinterOptions(ctx context.Context, fs afero.Fs, logger log.Logger, pkg *xpkg.Package) (*schema.SchemaStore, []linter.LinterOption, error) {
+	config, err := c.getConfig(fs)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errLoadConfig)
+	}
+	schemaStore, err := c.buildSchemaStore(ctx, fs, logger, config, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts, err := linter.WithCustomRules(config.CustomRules)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errLoadCustomRules)
+	}
+	pluginOpts, err := linter.WithPlugins(config.Plugins)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errLoadPlugins)
+	}
+	opts = append(opts, pluginOpts...)
+	opts = append(opts, linter.WithComplexityThresholds(config.Complexity)...)
+	severityOpts, err := linter.WithSeverities(config.Severities)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errLoadConfig)
+	}
+	opts = append(opts, severityOpts...)
+	return schemaStore, opts, nil
+}
+
+// buildSchemaStore loads the configured dependencies of pkg and registers the
+// schemas of pkg and its dependencies in a new SchemaStore.
+func (c *packageFlags) buildSchemaStore(ctx context.Context, fs afero.Fs, logger log.Logger, config config.Configuration, pkg *xpkg.Package) (*schema.SchemaStore, error) {
+	homeDir, err := c.getHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	fetcher := fetch.NewFsCacheFetcher(
+		afero.NewBasePathFs(fs, filepath.Join(homeDir, "images")),
+		fetch.NewRemoteFetcher(),
+	)
+
+	pkgDeps, err := parse.LoadPackageDependencies(config.AdditionalPackages, parse.NewPackageImageParser(fetcher))
+	if err != nil {
+		return nil, errors.Wrap(err, errLoadPackageDependencies)
+	}
+	schemaDeps, err := parse.LoadSchemaDependencies(config.AdditionalSchemas, parse.NewSchemaParser(ctx, fs, afero.NewBasePathFs(fs, filepath.Join(homeDir, "schemas"))))
+	if err != nil {
+		return nil, errors.Wrap(err, errLoadAdditionalSchemas)
+	}
+	pkgDeps = append(pkgDeps, schemaDeps...)
+
+	schemaStore := schema.NewSchemaStore()
+	// Kubernetes schemas are registered first, so CRDs of the same GVK
+	// replace them.
+	if err := registerKubernetesSchemas(fs, config.Kubernetes, schemaStore); err != nil {
+		return nil, errors.Wrap(err, errLoadKubernetesSchemas)
+	}
+	for _, p := range append([]*xpkg.Package{pkg}, pkgDeps...) {
+		for _, skipped := range schemaStore.RegisterPackage(p) {
+			logger.Log(errors.Wrap(skipped, errRegisterPackageSchema))
+		}
+	}
+	if config.EnvironmentSchema != "" {
+		envSchema, err := loadEnvironmentSchema(fs, config.EnvironmentSchema)
+		if err != nil {
+			return nil, errors.Wrap(err, errLoadEnvironmentSchema)
+		}
+		schemaStore.RegisterEnvironmentSchema(envSchema)
+	}
+	return schemaStore, nil
+}
+
+// getHomeDir returns the directory of the caches of the linter.
+func (c *packageFlags) getHomeDir() (string, error) {
 	var homeDir string
 
 	if c.Home != "" {
@@ -98,10 +279,10 @@ func (c *lintPackageCmd) getImageCacheDir() (string, error) {
 		}
 		homeDir = filepath.Join(userHome, "crossplane-lint")
 	}
-	return filepath.Join(homeDir, "images"), nil
+	return homeDir, nil
 }
 
-func (c *lintPackageCmd) getConfig(fs afero.Fs) (config.Configuration, error) {
+func (c *packageFlags) getConfig(fs afero.Fs) (config.Configuration, error) {
 	data, err := afero.ReadFile(fs, c.Config)
 	if err != nil {
 		return config.Configuration{}, err
@@ -110,7 +291,55 @@ func (c *lintPackageCmd) getConfig(fs afero.Fs) (config.Configuration, error) {
 	if err := yaml.Unmarshal(data, &con); err != nil {
 		return config.DefaultConfig, errorIgnore(err, os.IsNotExist)
 	}
-	return con, nil
+	return con, con.Validate()
+}
+
+// registerKubernetesSchemas registers the schemas of the built-in Kubernetes
+// kinds in schemaStore. Uses the configured OpenAPI documents if any and the
+// bundled schemas otherwise.
+func registerKubernetesSchemas(fs afero.Fs, k config.KubernetesSchemas, schemaStore *schema.SchemaStore) error {
+	if k.Disabled {
+		return nil
+	}
+	if len(k.Schemas) == 0 {
+		if version := kubernetesMinorVersion(k.Version); version != "" && version != schema.BundledKubernetesVersion {
+			return errors.Errorf(errFmtKubernetesVersion, version, schema.BundledKubernetesVersion)
+		}
+		schemaStore.RegisterBundledKubernetesSchemas()
+		return nil
+	}
+	for _, path := range k.Schemas {
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return errors.Wrapf(err, errFmtReadOpenAPI, path)
+		}
+		if _, err := schemaStore.RegisterOpenAPISchemas(data, path); err != nil {
+			return errors.Wrap(err, path)
+		}
+	}
+	return nil
+}
+
+// kubernetesMinorVersion returns the major and minor version of a Kubernetes
+// version like v1.24.3.
+func kubernetesMinorVersion(version string) string {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return strings.Join(parts, ".")
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func loadEnvironmentSchema(fs afero.Fs, path string) (*extv1.JSONSchemaProps, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	props := &extv1.JSONSchemaProps{}
+	if err := yaml.Unmarshal(data, props); err != nil {
+		return nil, err
+	}
+	return props, nil
 }
 
 func errorIgnore(err error, filter func(error) bool) error {
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/go-log/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/lsp"
+	linter "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	errResolvePackagePath = "failed to resolve package path"
+)
+
+type lspCmd struct {
+	Package string `short:"f" help:"Path to the package that is edited" type:"existingDir" default:"."`
+
+	packageFlags `embed:""`
+}
+
+func (c *lspCmd) Run(ctx context.Context, fs afero.Fs, logger log.Logger) error {
+	// Editors identify documents by absolute paths.
+	dir, err := filepath.Abs(c.Package)
+	if err != nil {
+		return errors.Wrap(err, errResolvePackagePath)
+	}
+	parser := parse.NewPackageDirectoryParser(fs)
+	pkg, err := parser.ParsePackage(dir)
+	if err != nil {
+		return errors.Wrap(err, errParsePackage)
+	}
+
+	schemaStore, opts, err := c.buildLinterOptions(ctx, fs, logger, pkg)
+	if err != nil {
+		return err
+	}
+	// Plugins are too slow to run on every edit.
+	typeOpts := append(append([]linter.LinterOption{}, opts...), linter.WithoutPlugins())
+
+	server := lsp.NewServer(pkg, parser, schemaStore, linter.Newlinter(schemaStore, opts...), linter.Newlinter(schemaStore, typeOpts...), logger)
+	return server.Serve(os.Stdin, os.Stdout)
+}
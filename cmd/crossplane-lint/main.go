@@ -1,19 +1,34 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/alecthomas/kong"
 	"github.com/go-log/log"
 	fmtLog "github.com/go-log/log/fmt"
+	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 )
 
+const (
+	// exitCodeIssues is returned if the linter discovered issues.
+	exitCodeIssues = 1
+
+	// exitCodeFailure is returned if the linter failed to run.
+	exitCodeFailure = 2
+)
+
 var cli struct {
 	// Lint struct {
 	// 	Package lintPackageCmd `cmd:"package" help:"Scan a package for issues"`
 	// 	} `cmd:"lint"`
 	Package lintPackageCmd `cmd:"package" help:"Scan a directory of compositions and XRDs"`
+	Graph   graphCmd       `cmd:"graph" help:"Export the dependency graph of the XRDs and compositions of a package"`
+	Stats   statsCmd       `cmd:"stats" help:"Print complexity metrics of the compositions of a package"`
+	Lsp     lspCmd         `cmd:"lsp" help:"Run a language server for a package over stdio"`
 	Version versionCmd     `cmd:"version" help:"Print version information"`
 }
 
@@ -23,13 +38,29 @@ func main() {
 	fs := afero.NewOsFs()
 	logger := fmtLog.NewFromWriter(os.Stderr)
 
+	// Commands stop when the process is interrupted.
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
 	ctx := kong.Parse(&cli,
 		kong.Name("crossplane-lint"),
 		kong.Description("Linting of crossplane compositions and XRDs"),
 		kong.BindTo(fs, (*afero.Fs)(nil)),
 		kong.BindTo(logger, (*log.Logger)(nil)),
+		kong.BindTo(runCtx, (*context.Context)(nil)),
+		kong.Exit(func(code int) {
+			if code != 0 {
+				code = exitCodeFailure
+			}
+			os.Exit(code)
+		}),
 	)
 
 	err := ctx.Run()
+	stop()
+	issuesErr := &issuesError{}
+	if errors.As(err, &issuesErr) {
+		ctx.Errorf("%s", err)
+		os.Exit(exitCodeIssues)
+	}
 	ctx.FatalIfErrorf(err)
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/go-log/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter/rules"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/stats"
+)
+
+const (
+	errWriteStats = "failed to write stats"
+)
+
+type statsCmd struct {
+	Package string `short:"f" help:"Path to the package whose compositions should be measured" type:"existingDir" required:"true"`
+	Output  string `short:"o" enum:"table,json" default:"table" help:"Output format of the stats (table or json)."`
+
+	packageFlags `embed:""`
+}
+
+func (c *statsCmd) Run(ctx context.Context, fs afero.Fs, logger log.Logger) error {
+	pkg, err := parse.NewPackageDirectoryParser(fs).ParsePackage(c.Package)
+	if err != nil {
+		return errors.Wrap(err, errParsePackage)
+	}
+	config, err := c.getConfig(fs)
+	if err != nil {
+		return errors.Wrap(err, errLoadConfig)
+	}
+	schemaStore, err := c.buildSchemaStore(ctx, fs, logger, config, pkg)
+	if err != nil {
+		return err
+	}
+	report := stats.Compute(pkg, schemaStore)
+	report.FieldUsage = rules.ComputeXRDFieldUsage(pkg)
+	return errors.Wrap(report.Write(os.Stdout, c.Output), errWriteStats)
+}
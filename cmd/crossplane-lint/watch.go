@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-log/log"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/baseline"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/print"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	errFmtParseFile    = "failed to parse %s"
+	errRegisterSchemas = "failed to register schemas"
+)
+
+// watch lints pkg and lints it again whenever watcher detects a change until
+// the process is interrupted. Only changed files are parsed again. Schemas of
+// the dependencies stay registered in schemaStore.
+func (c *lintPackageCmd) watch(ctx context.Context, watcher *parse.DirectoryWatcher, parser *parse.PackageDirectoryParser, pkg *xpkg.Package, schemaStore *schema.SchemaStore, pkgLinter lint.Linter, base *baseline.Baseline, logger log.Logger) error {
+	printer := c.buildPrinter()
+	report, _, err := c.lint(ctx, pkgLinter, pkg, base)
+	if err := logRunError(err, logger); err != nil {
+		return ignoreCanceled(ctx, err)
+	}
+	if err := printer.PrintReport(report); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%d issues discovered, watching %s for changes\n", len(report.Issues), c.Package)
+
+	ticker := time.NewTicker(c.WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		changes, err := watcher.Poll()
+		if err != nil {
+			logger.Log(errors.Wrap(err, errWatchPackage))
+			continue
+		}
+		if changes.Empty() {
+			continue
+		}
+		applyFileChanges(parser, pkg, schemaStore, changes, logger)
+
+		next, _, err := c.lint(ctx, pkgLinter, pkg, base)
+		if err := logRunError(err, logger); err != nil {
+			return ignoreCanceled(ctx, err)
+		}
+		added, fixed := next.Diff(report)
+		if err := printReportDiff(printer, added, fixed, len(next.Issues)); err != nil {
+			return err
+		}
+		report = next
+	}
+}
+
+// applyFileChanges parses the modified files again and updates pkg and the
+// schemas of schemaStore accordingly. Files that fail to parse keep their
+// previous entry. Schemas of removed files are unregistered.
+func applyFileChanges(parser *parse.PackageDirectoryParser, pkg *xpkg.Package, schemaStore *schema.SchemaStore, changes parse.FileChanges, logger log.Logger) {
+	definitionsChanged := false
+	for _, path := range changes.Removed {
+		definitionsChanged = definitionsChanged || definesSchemas(pkg, path)
+		pkg.RemoveEntry(path)
+	}
+	modified := map[string]bool{}
+	for _, path := range changes.Modified {
+		entry, err := parser.ParseFile(path)
+		if err != nil {
+			logger.Log(errors.Wrapf(err, errFmtParseFile, path))
+			continue
+		}
+		definitionsChanged = definitionsChanged || definesSchemas(pkg, path) || isDefinition(&entry)
+		pkg.ReplaceEntry(entry)
+		modified[path] = true
+	}
+	if !definitionsChanged {
+		return
+	}
+	for _, skipped := range schemaStore.UpdatePackage(pkg) {
+		// Definitions of unchanged files were logged before.
+		if modified[skipped.Entry.Source] {
+			logger.Log(errors.Wrap(skipped, errRegisterSchemas))
+		}
+	}
+}
+
+// definesSchemas determines if the entry of pkg with source is registered in
+// the SchemaStore.
+func definesSchemas(pkg *xpkg.Package, source string) bool {
+	for i := range pkg.Entries {
+		if pkg.Entries[i].Source == source && isDefinition(&pkg.Entries[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDefinition(e *xpkg.PackageEntry) bool {
+	return e.IsCRD() || e.IsXRD() || e.IsFunction()
+}
+
+// printReportDiff prints the issues that were added and fixed by the last
+// change.
+func printReportDiff(printer print.Printer, added, fixed []lint.Issue, total int) error {
+	fmt.Fprintf(os.Stdout, "--- %s: %d new, %d fixed, %d total issues\n", time.Now().Format("15:04:05"), len(added), len(fixed), total)
+	if len(added) > 0 {
+		fmt.Fprintln(os.Stdout, "New issues:")
+		if err := printer.PrintReport(lint.LinterReport{Issues: added}); err != nil {
+			return err
+		}
+	}
+	if len(fixed) > 0 {
+		fmt.Fprintln(os.Stdout, "Fixed issues:")
+		if err := printer.PrintReport(lint.LinterReport{Issues: fixed}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logRunError logs err and returns nil if it is a *lint.RunError, so watching
+// continues with the issues of the rules that did run. Returns all other
+// errors.
+func logRunError(err error, logger log.Logger) error {
+	runErr := &lint.RunError{}
+	if errors.As(err, &runErr) {
+		logger.Log(errors.Wrap(err, errLintPackage))
+		return nil
+	}
+	return err
+}
+
+// ignoreCanceled returns nil if err is caused by interrupting the process.
+func ignoreCanceled(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	fmtLog "github.com/go-log/log/fmt"
+	"github.com/spf13/afero"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	watchCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: buckets.s3.aws.upbound.io
+spec:
+  group: s3.aws.upbound.io
+  names:
+    kind: Bucket
+    plural: buckets
+  scope: Cluster
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+	watchXRD = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xexamples.example.org
+spec:
+  group: example.org
+  names:
+    kind: XExample
+    plural: xexamples
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              name:
+                type: string
+`
+	watchComposition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XExample
+  resources: []
+`
+)
+
+func TestApplyFileChanges(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"pkg/a-crd.yaml":         watchCRD,
+		"pkg/b-xrd.yaml":         watchXRD,
+		"pkg/c-composition.yaml": watchComposition,
+	}
+	for path, content := range files {
+		if err := afero.WriteFile(fs, path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile(...): unexpected error: %v", err)
+		}
+	}
+	parser := parse.NewPackageDirectoryParser(fs)
+	pkg := &xpkg.Package{Source: "pkg"}
+	for _, path := range []string{"pkg/a-crd.yaml", "pkg/b-xrd.yaml", "pkg/c-composition.yaml"} {
+		e, err := parser.ParseFile(path)
+		if err != nil {
+			t.Fatalf("ParseFile(...): unexpected error: %v", err)
+		}
+		pkg.Entries = append(pkg.Entries, e)
+	}
+	store := schema.NewSchemaStore()
+	store.RegisterPackage(pkg)
+	logs := &bytes.Buffer{}
+	logger := fmtLog.NewFromWriter(logs)
+
+	// Remove the CRD before the XRD, so the entries of the XRD and the
+	// composition move within the package.
+	if err := fs.Remove("pkg/a-crd.yaml"); err != nil {
+		t.Fatalf("Remove(...): unexpected error: %v", err)
+	}
+	applyFileChanges(parser, pkg, store, parse.FileChanges{Removed: []string{"pkg/a-crd.yaml"}}, logger)
+	edited := watchXRD + `              size:
+                type: string
+`
+	if err := afero.WriteFile(fs, "pkg/b-xrd.yaml", []byte(edited), 0o600); err != nil {
+		t.Fatalf("WriteFile(...): unexpected error: %v", err)
+	}
+	applyFileChanges(parser, pkg, store, parse.FileChanges{Modified: []string{"pkg/b-xrd.yaml"}}, logger)
+
+	if logs.Len() > 0 {
+		t.Errorf("applyFileChanges(...): unexpected logs: %s", logs.String())
+	}
+	if got := len(pkg.Entries); got != 2 {
+		t.Errorf("applyFileChanges(...): want 2 entries, got %d", got)
+	}
+	if crd := store.GetCRDSchema(k8sschema.GroupVersionKind{Group: "s3.aws.upbound.io", Version: "v1beta1", Kind: "Bucket"}); crd != nil {
+		t.Errorf("applyFileChanges(...): want schema of removed CRD to be unregistered")
+	}
+	xr := k8sschema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XExample"}
+	crd := store.GetCRDSchema(xr)
+	if crd == nil {
+		t.Fatalf("applyFileChanges(...): want schema of XExample")
+	}
+	if _, exists := crd.Schema.OpenAPIV3Schema.Properties["spec"].Properties["size"]; !exists {
+		t.Errorf("applyFileChanges(...): want edited schema of XExample with spec.size")
+	}
+	if origin := store.GetSchemaOrigin(xr); origin == nil || origin.Entry.Source != "pkg/b-xrd.yaml" {
+		t.Errorf("applyFileChanges(...): want origin pkg/b-xrd.yaml of XExample, got %v", origin)
+	}
+	if defs := store.GetKindDefinitions(xr.GroupKind()); len(defs) != 1 || defs[0].Entry.Source != "pkg/b-xrd.yaml" {
+		t.Errorf("applyFileChanges(...): want single definition of XExample in pkg/b-xrd.yaml, got %v", defs)
+	}
+	if conflicts := store.GetSchemaConflicts(); len(conflicts) > 0 {
+		t.Errorf("applyFileChanges(...): unexpected conflicts: %v", conflicts)
+	}
+}
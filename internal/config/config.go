@@ -1,11 +1,195 @@
 package config
 
+import (
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+const (
+	errFmtRuleSeverity       = "invalid severity of rule %s"
+	errFmtCustomRuleSeverity = "invalid severity of custom rule %s"
+)
+
 type PackageDescriptor struct {
 	Image string `json:"image"`
 }
 
+// SchemaDescriptor points to CRDs and XRDs that are not part of a package.
+// Either Path or URL must be set.
+type SchemaDescriptor struct {
+	// Path of a manifest file or of a directory that is searched recursively
+	// for YAML and JSON files.
+	Path string `json:"path,omitempty"`
+
+	// URL of a manifest file. Fetched files are cached like package images.
+	URL string `json:"url,omitempty"`
+}
+
+// Source returns the URL of d if set and its path otherwise.
+func (d SchemaDescriptor) Source() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	return d.Path
+}
+
 type Configuration struct {
 	AdditionalPackages []PackageDescriptor `json:"additionalPackages"`
+
+	// AdditionalSchemas are CRDs and XRDs from local files, directories and
+	// URLs, for example of Helm charts.
+	AdditionalSchemas []SchemaDescriptor `json:"additionalSchemas,omitempty"`
+
+	// EnvironmentSchema is the path to an OpenAPI v3 schema of the data of
+	// composition environments. Environment field paths are only validated if
+	// set.
+	EnvironmentSchema string `json:"environmentSchema,omitempty"`
+
+	// CustomRules are additional rules defined as CEL expressions.
+	CustomRules []CustomRule `json:"customRules,omitempty"`
+
+	// Plugins are executables that implement additional rules.
+	Plugins []Plugin `json:"plugins,omitempty"`
+
+	// Severities overrides the severity of the issues of rules by rule name.
+	// Either error, warning or info.
+	Severities map[string]string `json:"severities,omitempty"`
+
+	// Kubernetes configures the schemas of the built-in Kubernetes kinds.
+	Kubernetes KubernetesSchemas `json:"kubernetes,omitempty"`
+
+	// Complexity configures the thresholds of composition.checkComplexity.
+	// The rule is only enabled if at least one threshold is set.
+	Complexity ComplexityThresholds `json:"complexity,omitempty"`
+}
+
+// Validate checks the severities of c.
+func (c Configuration) Validate() error {
+	for name, s := range c.Severities {
+		if _, err := lint.ParseSeverity(s); err != nil {
+			return errors.Wrapf(err, errFmtRuleSeverity, name)
+		}
+	}
+	for _, r := range c.CustomRules {
+		if r.Severity == "" {
+			continue
+		}
+		if _, err := lint.ParseSeverity(r.Severity); err != nil {
+			return errors.Wrapf(err, errFmtCustomRuleSeverity, r.Name)
+		}
+	}
+	return nil
+}
+
+// ComplexityThresholds are the maximum values of the complexity metrics of a
+// composition. Zero disables a threshold.
+type ComplexityThresholds struct {
+	// MaxResources is the maximum number of composed resources.
+	MaxResources int `json:"maxResources,omitempty"`
+
+	// MaxPatches is the maximum number of patches of resources and patch
+	// sets.
+	MaxPatches int `json:"maxPatches,omitempty"`
+
+	// MaxTransformDepth is the maximum number of transforms of a patch.
+	MaxTransformDepth int `json:"maxTransformDepth,omitempty"`
+
+	// MaxKinds is the maximum number of distinct composed kinds.
+	MaxKinds int `json:"maxKinds,omitempty"`
+
+	// MaxProviders is the maximum number of distinct packages providing the
+	// composed kinds.
+	MaxProviders int `json:"maxProviders,omitempty"`
+
+	// MaxSize is the maximum size of the manifest in bytes.
+	MaxSize int `json:"maxSize,omitempty"`
+}
+
+// IsSet determines if at least one threshold of t is set.
+func (t ComplexityThresholds) IsSet() bool {
+	return t != ComplexityThresholds{}
+}
+
+// KubernetesSchemas configures the schemas of the built-in Kubernetes kinds,
+// such as Secret or ConfigMap.
+type KubernetesSchemas struct {
+	// Version of Kubernetes, for example 1.24. Defaults to the version whose
+	// schemas are bundled. Other versions require Schemas.
+	Version string `json:"version,omitempty"`
+
+	// Schemas are paths to OpenAPI v3 or v2 documents of the Kubernetes API,
+	// for example the output of `kubectl get --raw /openapi/v3/api/v1`. The
+	// bundled schemas are not used if set.
+	Schemas []string `json:"schemas,omitempty"`
+
+	// Disabled disables the schemas of the built-in Kubernetes kinds.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Plugin is an executable that receives the package on stdin and writes the
+// discovered issues to stdout, both as JSON.
+type Plugin struct {
+	// Name of the plugin. Defaults to the file name of Command.
+	Name string `json:"name,omitempty"`
+
+	// Command is the path of the executable.
+	Command string `json:"command"`
+
+	// Args passed to Command.
+	Args []string `json:"args,omitempty"`
+
+	// Timeout of a single plugin run, for example 10s. Defaults to 30s.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Targets of custom rules.
+const (
+	// CustomRuleTargetObject matches the objects of a package.
+	CustomRuleTargetObject = "Object"
+
+	// CustomRuleTargetComposedBase matches the bases of composed resources.
+	CustomRuleTargetComposedBase = "ComposedBase"
+)
+
+// CustomRule is a rule that evaluates a CEL expression against each matching
+// object. An issue is reported for each object the expression evaluates to
+// false for.
+type CustomRule struct {
+	// Name of the rule. Must be unique.
+	Name string `json:"name"`
+
+	// Severity of the reported issues. Either error, warning or info.
+	// Defaults to error.
+	Severity string `json:"severity,omitempty"`
+
+	// Match selects the objects the rule is evaluated for.
+	Match CustomRuleMatch `json:"match"`
+
+	// Expression that must evaluate to true for valid objects. The object is
+	// available as variable object. Rules for composed bases can access the
+	// composed resource template as resource and the Composition as
+	// composition.
+	Expression string `json:"expression"`
+
+	// Message of the reported issues.
+	Message string `json:"message,omitempty"`
+
+	// Path of the reported issues relative to the object, for example
+	// spec.deletionPolicy.
+	Path string `json:"path,omitempty"`
+}
+
+// CustomRuleMatch selects objects by target and GVK.
+type CustomRuleMatch struct {
+	// Target of the rule. Either Object or ComposedBase. Defaults to Object.
+	Target string `json:"target,omitempty"`
+
+	// APIVersion of matching objects. Matches any if empty.
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of matching objects. Matches any if empty.
+	Kind string `json:"kind,omitempty"`
 }
 
 var (
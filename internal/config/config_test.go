@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		config  Configuration
+		wantErr bool
+	}{
+		"Valid": {
+			reason: "Known severities are valid.",
+			config: Configuration{
+				Severities:  map[string]string{"xrd.checkNames": "info"},
+				CustomRules: []CustomRule{{Name: "r", Severity: "warning"}, {Name: "default"}},
+			},
+		},
+		"UnknownRuleSeverity": {
+			reason:  "Unknown severities of rules are rejected.",
+			config:  Configuration{Severities: map[string]string{"xrd.checkNames": "fatal"}},
+			wantErr: true,
+		},
+		"UnknownCustomRuleSeverity": {
+			reason:  "Unknown severities of custom rules are rejected.",
+			config:  Configuration{CustomRules: []CustomRule{{Name: "r", Severity: "Error"}}},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nValidate(): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+		})
+	}
+}
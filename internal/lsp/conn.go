@@ -0,0 +1,80 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	headerContentLength = "Content-Length"
+
+	errReadHeader         = "failed to read message header"
+	errFmtInvalidLength   = "invalid content length '%s'"
+	errReadContent        = "failed to read message content"
+	errMarshalMessage     = "failed to marshal message"
+	errWriteMessage       = "failed to write message"
+	errMissingContentSize = "missing content length header"
+)
+
+// conn reads and writes JSON-RPC messages framed by a Content-Length header.
+type conn struct {
+	reader *textproto.Reader
+	in     *bufio.Reader
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newConn(in io.Reader, out io.Writer) *conn {
+	buffered := bufio.NewReader(in)
+	return &conn{
+		reader: textproto.NewReader(buffered),
+		in:     buffered,
+		out:    out,
+	}
+}
+
+// read the next message. Returns io.EOF if the input is closed.
+func (c *conn) read() ([]byte, error) {
+	header, err := c.reader.ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, errors.Wrap(err, errReadHeader)
+	}
+	rawLength := header.Get(headerContentLength)
+	if rawLength == "" {
+		return nil, errors.New(errMissingContentSize)
+	}
+	length, err := strconv.Atoi(rawLength)
+	if err != nil {
+		return nil, errors.Errorf(errFmtInvalidLength, rawLength)
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(c.in, content); err != nil {
+		return nil, errors.Wrap(err, errReadContent)
+	}
+	return content, nil
+}
+
+// write msg as JSON.
+func (c *conn) write(msg any) error {
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, errMarshalMessage)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.out, "%s: %d\r\n\r\n%s", headerContentLength, len(content), content); err != nil {
+		return errors.Wrap(err, errWriteMessage)
+	}
+	return nil
+}
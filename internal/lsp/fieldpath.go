@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+)
+
+// fieldPathTarget is the object a field path is resolved against.
+type fieldPathTarget struct {
+	// GVK of the object if it is not the environment.
+	GVK schema.GroupVersionKind
+
+	// Environment is set if the field path points into the environment.
+	Environment bool
+}
+
+// patchSide is one side of a patch.
+type patchSide int
+
+const (
+	sideComposite patchSide = iota
+	sideResource
+	sideEnvironment
+)
+
+// resolveFieldPathTarget determines the object the field path at path in the
+// Composition root refers to. path must end with a field path key as returned
+// by findFieldPathAt.
+func resolveFieldPathTarget(root *yaml.Node, path nodePath) (fieldPathTarget, bool) {
+	if len(path) == 0 {
+		return fieldPathTarget{}, false
+	}
+	key, _ := path[len(path)-1].(string)
+	if key == keyValueFromFieldPath {
+		return compositeTarget(root)
+	}
+
+	patchPath := path[:len(path)-1]
+	if len(path) >= 5 && path[len(path)-3] == "variables" && path[len(path)-4] == "combine" {
+		patchPath = path[:len(path)-4]
+	}
+	from, to := patchSides(xpv1.PatchType(mappingString(nodeAt(root, patchPath), "type")))
+	side := from
+	if key == keyToFieldPath {
+		side = to
+	}
+
+	switch side {
+	case sideComposite:
+		return compositeTarget(root)
+	case sideEnvironment:
+		return fieldPathTarget{Environment: true}, true
+	}
+	if isEnvironmentPatch(patchPath) {
+		return fieldPathTarget{Environment: true}, true
+	}
+	return resourceTarget(root, patchPath)
+}
+
+// patchSides returns the sides a patch of type t reads from and writes to.
+func patchSides(t xpv1.PatchType) (from, to patchSide) {
+	switch t {
+	case xpv1.PatchTypeToCompositeFieldPath, xpv1.PatchTypeCombineToComposite:
+		return sideResource, sideComposite
+	case xpkg.PatchTypeFromEnvironmentFieldPath, xpkg.PatchTypeCombineFromEnvironment:
+		return sideEnvironment, sideResource
+	case xpkg.PatchTypeToEnvironmentFieldPath, xpkg.PatchTypeCombineToEnvironment:
+		return sideResource, sideEnvironment
+	default:
+		return sideComposite, sideResource
+	}
+}
+
+// isEnvironmentPatch determines if patchPath points to a patch in
+// spec.environment.patches. The environment takes the role of the composed
+// resource for those patches.
+func isEnvironmentPatch(patchPath nodePath) bool {
+	n := len(patchPath)
+	return n >= 3 && patchPath[n-3] == "environment" && patchPath[n-2] == "patches"
+}
+
+func compositeTarget(root *yaml.Node) (fieldPathTarget, bool) {
+	ref := nodeAt(root, nodePath{"spec", "compositeTypeRef"})
+	return gvkTarget(mappingString(ref, "apiVersion"), mappingString(ref, "kind"))
+}
+
+// resourceTarget returns the base of the resource the patch at patchPath
+// belongs to. Patches of a patchSet resolve to the first resource that
+// includes the patchSet.
+func resourceTarget(root *yaml.Node, patchPath nodePath) (fieldPathTarget, bool) {
+	n := len(patchPath)
+	if n < 4 || patchPath[n-2] != "patches" {
+		return fieldPathTarget{}, false
+	}
+	templateRoot := append(nodePath{}, patchPath[:n-4]...)
+	container := append(nodePath{}, patchPath[:n-2]...)
+
+	var base *yaml.Node
+	switch patchPath[n-4] {
+	case "resources":
+		base = mappingValue(nodeAt(root, container), "base")
+	case "patchSets":
+		base = patchSetBase(nodeAt(root, append(templateRoot, "resources")), mappingString(nodeAt(root, container), "name"))
+	}
+	if base == nil {
+		return fieldPathTarget{}, false
+	}
+	return gvkTarget(mappingString(base, "apiVersion"), mappingString(base, "kind"))
+}
+
+// patchSetBase returns the base of the first resource that includes the
+// patchSet name.
+func patchSetBase(resources *yaml.Node, name string) *yaml.Node {
+	if resources == nil || resources.Kind != yaml.SequenceNode || name == "" {
+		return nil
+	}
+	for _, r := range resources.Content {
+		patches := mappingValue(r, "patches")
+		if patches == nil || patches.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, p := range patches.Content {
+			if mappingString(p, "type") == string(xpv1.PatchTypePatchSet) && mappingString(p, "patchSetName") == name {
+				return mappingValue(r, "base")
+			}
+		}
+	}
+	return nil
+}
+
+func gvkTarget(apiVersion, kind string) (fieldPathTarget, bool) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || kind == "" {
+		return fieldPathTarget{}, false
+	}
+	return fieldPathTarget{GVK: gv.WithKind(kind)}, true
+}
+
+// String returns a human readable name of t.
+func (t fieldPathTarget) String() string {
+	if t.Environment {
+		return "environment"
+	}
+	return t.GVK.String()
+}
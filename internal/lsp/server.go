@@ -0,0 +1,680 @@
+// Package lsp implements a Language Server Protocol server that reports the
+// issues of a package as diagnostics of the documents opened in an editor.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/go-log/log"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+	linter "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter/rules"
+	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	serverName       = "crossplane-lint"
+	diagnosticSource = "crossplane-lint"
+
+	// defaultLintDelay is the time without edits after which the package is
+	// linted while a document is edited.
+	defaultLintDelay = 300 * time.Millisecond
+
+	errFmtUnsupportedURI   = "unsupported document URI '%s'"
+	errFmtDocumentNotOpen  = "document '%s' is not open"
+	errExitWithoutShutdown = "received exit notification before shutdown"
+	errFmtHandleMethod     = "failed to handle %s"
+	errParseDocument       = "failed to parse document"
+	errRegisterSchema      = "failed to register document schemas"
+	errFmtReloadDocument   = "failed to reload '%s'"
+	errFmtUnknownSchema    = "schema of '%s' is unknown"
+)
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// Server is a Language Server Protocol server for a single package.
+type Server struct {
+	conn   *conn
+	pkg    *xpkg.Package
+	parser *parse.PackageDirectoryParser
+	store  *lintschema.SchemaStore
+	linter lint.Linter
+	logger log.Logger
+
+	// typeLinter lints the package while documents are edited. It leaves out
+	// slow rules like plugins, their issues are updated when a document is
+	// opened, saved or closed.
+	typeLinter lint.Linter
+	lintDelay  time.Duration
+	lintTimer  <-chan time.Time
+
+	// pluginIssues are the issues of plugins of the last run of linter.
+	pluginIssues []lint.Issue
+
+	// Text of the open documents by path.
+	documents map[string]string
+
+	// URI of the open documents by path.
+	uris     map[string]string
+	shutdown bool
+}
+
+// NewServer creates a new Server for pkg. store must contain the schemas of
+// pkg and its dependencies. linter lints pkg when documents are opened,
+// saved or closed, typeLinter while they are edited. Closed documents are
+// parsed again with parser.
+func NewServer(pkg *xpkg.Package, parser *parse.PackageDirectoryParser, store *lintschema.SchemaStore, linter, typeLinter lint.Linter, logger log.Logger) *Server {
+	return &Server{
+		pkg:        pkg,
+		parser:     parser,
+		store:      store,
+		linter:     linter,
+		typeLinter: typeLinter,
+		lintDelay:  defaultLintDelay,
+		logger:     logger,
+		documents:  map[string]string{},
+		uris:       map[string]string{},
+	}
+}
+
+// message is the result of reading from the connection.
+type message struct {
+	raw []byte
+	err error
+}
+
+// Serve reads requests from in and writes responses to out until the client
+// sends the exit notification or closes in.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.conn = newConn(in, out)
+	messages := make(chan message)
+	done := make(chan struct{})
+	defer close(done)
+	go s.readMessages(messages, done)
+	for {
+		var m message
+		select {
+		case <-s.lintTimer:
+			s.lintTimer = nil
+			if rerr := s.lintDocuments(false); rerr != nil {
+				return errors.New(rerr.Message)
+			}
+			continue
+		case m = <-messages:
+		}
+		raw, err := m.raw, m.err
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		req := request{}
+		if err := json.Unmarshal(raw, &req); err != nil {
+			if err := s.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New(errExitWithoutShutdown)
+			}
+			return nil
+		}
+		result, rerr := s.handle(req)
+		if req.ID == nil {
+			if rerr != nil {
+				s.logger.Logf(errFmtHandleMethod+": %s", req.Method, rerr.Message)
+			}
+			continue
+		}
+		if rerr != nil {
+			err = s.replyError(req.ID, rerr.Code, rerr.Message)
+		} else {
+			err = s.conn.write(response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readMessages sends the messages read from the connection to messages until
+// reading fails or done is closed.
+func (s *Server) readMessages(messages chan<- message, done <-chan struct{}) {
+	for {
+		raw, err := s.conn.read()
+		select {
+		case messages <- message{raw: raw, err: err}:
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, message string) error {
+	return s.conn.write(errorResponse{
+		JSONRPC: jsonRPCVersion,
+		ID:      id,
+		Error:   responseError{Code: code, Message: message},
+	})
+}
+
+func (s *Server) handle(req request) (any, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync: textDocumentSyncOptions{OpenClose: true, Change: textDocumentSyncFull, Save: true},
+				HoverProvider:    true,
+				CompletionProvider: &completionOptions{
+					TriggerCharacters: []string{".", "["},
+				},
+			},
+			ServerInfo: serverInfo{Name: serverName},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		params := didOpenTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		changed, rerr := s.updateDocument(params.TextDocument.URI, params.TextDocument.Text)
+		if rerr != nil || !changed {
+			return nil, rerr
+		}
+		return nil, s.lintDocuments(true)
+	case "textDocument/didChange":
+		params := didChangeTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// The server only supports full document sync, so the last change
+		// contains the whole document.
+		changed, rerr := s.updateDocument(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		if changed {
+			// Edits in quick succession are linted once.
+			s.lintTimer = time.After(s.lintDelay)
+		}
+		return nil, rerr
+	case "textDocument/didSave":
+		params := didSaveTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		s.lintTimer = nil
+		return nil, s.lintDocuments(true)
+	case "textDocument/didClose":
+		params := didCloseTextDocumentParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil, s.closeDocument(params.TextDocument.URI)
+	case "textDocument/hover":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.hover(params)
+	case "textDocument/completion":
+		params := textDocumentPositionParams{}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.completion(params)
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method '%s' not found", req.Method)}
+}
+
+// updateDocument replaces the entry of the document uri in the package with
+// text. Returns true if the package changed and has to be linted. Parse errors
+// are published as diagnostics of the document.
+func (s *Server) updateDocument(uri, text string) (bool, *responseError) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return false, &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	s.documents[path] = text
+	s.uris[path] = uri
+
+	entry, err := parse.ParsePackageEntry([]byte(text), path)
+	if err != nil {
+		return false, s.publish(uri, []Diagnostic{errorDiagnostic(errors.Wrap(err, errParseDocument), text)})
+	}
+	if !s.inPackage(path) {
+		return false, s.publish(uri, nil)
+	}
+	definitionChanged := isDefinition(&entry) || isDefinition(s.entry(path))
+	s.pkg.ReplaceEntry(entry)
+	if definitionChanged {
+		if skipped := s.updateSchemas(path); skipped != nil {
+			return false, s.publish(uri, []Diagnostic{errorDiagnostic(errors.Wrap(skipped, errRegisterSchema), text)})
+		}
+	}
+	return true, nil
+}
+
+// updateSchemas registers the schemas of the package again. Returns the
+// skipped definition of the document path, if any.
+func (s *Server) updateSchemas(path string) error {
+	for _, skipped := range s.store.UpdatePackage(s.pkg) {
+		if skipped.Entry.Source == path {
+			return skipped
+		}
+	}
+	return nil
+}
+
+// entry returns the package entry of the document path or nil if there is
+// none.
+func (s *Server) entry(path string) *xpkg.PackageEntry {
+	for i := range s.pkg.Entries {
+		if s.pkg.Entries[i].Source == path {
+			return &s.pkg.Entries[i]
+		}
+	}
+	return nil
+}
+
+// isDefinition determines if e defines schemas or functions.
+func isDefinition(e *xpkg.PackageEntry) bool {
+	return e != nil && (e.IsCRD() || e.IsXRD() || e.IsFunction())
+}
+
+// closeDocument clears the diagnostics of the document uri. The document may
+// be closed without saving, so its package entry is parsed from the file
+// again.
+func (s *Server) closeDocument(uri string) *responseError {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	delete(s.documents, path)
+	delete(s.uris, path)
+	if rerr := s.publish(uri, nil); rerr != nil {
+		return rerr
+	}
+	if !s.inPackage(path) || !s.reloadEntry(path) {
+		return nil
+	}
+	return s.lintDocuments(true)
+}
+
+// reloadEntry replaces the package entry of path with the content of the
+// file. Removes the entry if the file does not exist or fails to parse.
+// Returns true if the package changed.
+func (s *Server) reloadEntry(path string) bool {
+	previous := s.entry(path)
+	entry, err := s.parser.ParseFile(path)
+	if err != nil {
+		if previous == nil {
+			return false
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			s.logger.Log(errors.Wrapf(err, errFmtReloadDocument, path))
+		}
+		s.pkg.RemoveEntry(path)
+	} else {
+		if previous != nil && previous.Raw == entry.Raw {
+			return false
+		}
+		s.pkg.ReplaceEntry(entry)
+	}
+	if isDefinition(previous) || (err == nil && isDefinition(&entry)) {
+		if skipped := s.updateSchemas(path); skipped != nil {
+			s.logger.Log(errors.Wrap(skipped, errRegisterSchema))
+		}
+	}
+	return true
+}
+
+// inPackage determines if the file path belongs to the package directory.
+func (s *Server) inPackage(path string) bool {
+	rel, err := filepath.Rel(s.pkg.Source, path)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// diagnosticSeverity returns the diagnostic severity of severity.
+func diagnosticSeverity(severity lint.Severity) int {
+	switch severity {
+	case lint.SeverityWarning:
+		return SeverityWarning
+	case lint.SeverityInfo:
+		return SeverityInformation
+	}
+	return SeverityError
+}
+
+// lintDocuments lints the package and publishes the issues of every open
+// document of the package. An edit may change the issues of other documents,
+// for example of compositions after an XRD edit. Documents whose text failed
+// to parse keep their parse error. Lints with linter if full is set and with
+// typeLinter otherwise. The plugin issues of the last full run are kept for
+// unchanged entries.
+func (s *Server) lintDocuments(full bool) *responseError {
+	l := s.typeLinter
+	if full {
+		l = s.linter
+	}
+	// The background context is never done, so Lint only fails if rules
+	// failed to run. The issues of the other rules are published anyway.
+	report, err := l.Lint(context.Background(), s.pkg)
+	if err != nil {
+		s.logger.Log(err)
+	}
+	issues := report.Issues
+	if full {
+		s.pluginIssues = nil
+		for _, issue := range issues {
+			if linter.IsPluginRule(issue.RuleName) {
+				s.pluginIssues = append(s.pluginIssues, issue)
+			}
+		}
+	} else {
+		for _, issue := range s.pluginIssues {
+			if issue.Entry == nil {
+				continue
+			}
+			if e := s.entry(issue.Entry.Source); e != nil && e.Raw == issue.Entry.Raw {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	diagnostics := map[string][]Diagnostic{}
+	for _, issue := range issues {
+		if issue.Entry == nil {
+			continue
+		}
+		path := issue.Entry.Source
+		text, open := s.documents[path]
+		if !open {
+			continue
+		}
+		diagnostics[path] = append(diagnostics[path], Diagnostic{
+			Range:    issueRange(issue, text),
+			Severity: diagnosticSeverity(issue.Severity),
+			Code:     issue.RuleName,
+			Source:   diagnosticSource,
+			Message:  issue.Description,
+		})
+	}
+	paths := make([]string, 0, len(s.uris))
+	for path := range s.uris {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if !s.inPackage(path) || !s.isLinted(path) {
+			continue
+		}
+		if err := s.publish(s.uris[path], diagnostics[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isLinted determines if the package entry of the document path has the
+// current text of the document.
+func (s *Server) isLinted(path string) bool {
+	e := s.entry(path)
+	return e != nil && e.Raw == s.documents[path]
+}
+
+// issueRange returns the range of the node issue.Path points to. Falls back to
+// the first line of the document if the path cannot be found.
+func issueRange(issue lint.Issue, text string) Range {
+	if len(issue.Path) == 0 {
+		return Range{}
+	}
+	root, err := issue.Entry.GetYamlNode()
+	if err != nil {
+		return Range{}
+	}
+	node, err := jsonpath.Find(root, issue.Path)
+	if err != nil || node == nil {
+		return Range{}
+	}
+	return nodeRange(node, text)
+}
+
+// errorDiagnostic returns a diagnostic for err. The diagnostic is placed on
+// the line mentioned in the error message, if any.
+func errorDiagnostic(err error, text string) Diagnostic {
+	d := Diagnostic{
+		Severity: SeverityError,
+		Source:   diagnosticSource,
+		Message:  err.Error(),
+	}
+	if match := yamlErrorLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+		if line, err := strconv.Atoi(match[1]); err == nil && line > 0 {
+			d.Range = lineRange(text, line-1)
+		}
+	}
+	return d
+}
+
+func lineRange(text string, line int) Range {
+	lines := strings.Split(text, "\n")
+	if line >= len(lines) {
+		return Range{}
+	}
+	lineText := strings.TrimRight(lines[line], "\r")
+	return Range{
+		Start: Position{Line: line},
+		End:   Position{Line: line, Character: utf16Column(lineText, utf8.RuneCountInString(lineText))},
+	}
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) *responseError {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	err := s.conn.write(notification{
+		JSONRPC: jsonRPCVersion,
+		Method:  "textDocument/publishDiagnostics",
+		Params:  publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+	if err != nil {
+		return &responseError{Code: codeInternalError, Message: err.Error()}
+	}
+	return nil
+}
+
+// fieldPathAt returns the field path value node at pos in the document uri
+// together with the schema of the object the field path refers to.
+func (s *Server) fieldPathAt(uri string, pos Position) (*yaml.Node, fieldPathTarget, *extv1.JSONSchemaProps, string, *responseError) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, fieldPathTarget{}, nil, "", &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	text, ok := s.documents[path]
+	if !ok {
+		return nil, fieldPathTarget{}, nil, "", &responseError{Code: codeInvalidParams, Message: fmt.Sprintf(errFmtDocumentNotOpen, uri)}
+	}
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(text), root); err != nil {
+		return nil, fieldPathTarget{}, nil, text, nil
+	}
+	value, valuePath := findFieldPathAt(root, pos.Line+1)
+	if value == nil {
+		return nil, fieldPathTarget{}, nil, text, nil
+	}
+	target, ok := resolveFieldPathTarget(root, valuePath)
+	if !ok {
+		return nil, fieldPathTarget{}, nil, text, nil
+	}
+	return value, target, s.targetSchema(target), text, nil
+}
+
+func (s *Server) targetSchema(t fieldPathTarget) *extv1.JSONSchemaProps {
+	if t.Environment {
+		return s.store.GetEnvironmentSchema()
+	}
+	crd := s.store.GetCRDSchema(t.GVK)
+	if crd == nil || crd.Schema == nil {
+		return nil
+	}
+	return crd.Schema.OpenAPIV3Schema
+}
+
+// hover shows the type and description of the field a field path points to.
+func (s *Server) hover(params textDocumentPositionParams) (any, *responseError) {
+	value, target, root, text, rerr := s.fieldPathAt(params.TextDocument.URI, params.Position)
+	if rerr != nil || value == nil || value.Value == "" {
+		return nil, rerr
+	}
+	valueRange := nodeRange(value, text)
+	if !valueRange.contains(params.Position) {
+		return nil, nil
+	}
+	return &hover{
+		Contents: markupContent{Kind: markupKindMarkdown, Value: describeFieldPath(root, target, value.Value)},
+		Range:    &valueRange,
+	}, nil
+}
+
+// describeFieldPath returns a markdown description of the field rawPath points
+// to in the schema root of target.
+func describeFieldPath(root *extv1.JSONSchemaProps, target fieldPathTarget, rawPath string) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "**%s**", rawPath)
+	if root == nil {
+		fmt.Fprintf(b, "\n\n"+errFmtUnknownSchema, target)
+		return b.String()
+	}
+	segments, err := fieldpath.Parse(rawPath)
+	if err != nil {
+		fmt.Fprintf(b, "\n\n%s", err)
+		return b.String()
+	}
+	schemas, err := rules.ResolveFieldPath(root, rawPath)
+	switch {
+	case err != nil:
+		fmt.Fprintf(b, "\n\n%s", err)
+	case len(schemas) <= len(segments):
+		fmt.Fprintf(b, "\n\n"+errFmtUnknownSchema, rawPath)
+	default:
+		field := schemas[len(schemas)-1]
+		if field.Type != "" {
+			fmt.Fprintf(b, " `%s`", field.Type)
+		}
+		if field.Description != "" {
+			fmt.Fprintf(b, "\n\n%s", field.Description)
+		}
+	}
+	fmt.Fprintf(b, "\n\n_%s_", target)
+	return b.String()
+}
+
+// completion offers the properties of the object the field path at the
+// cursor points into.
+func (s *Server) completion(params textDocumentPositionParams) (any, *responseError) {
+	items := []completionItem{}
+	value, _, root, text, rerr := s.fieldPathAt(params.TextDocument.URI, params.Position)
+	if rerr != nil {
+		return nil, rerr
+	}
+	if value == nil || root == nil {
+		return items, nil
+	}
+	prefix, ok := fieldPathPrefix(text, params.Position)
+	if !ok {
+		return items, nil
+	}
+	parent := ""
+	if i := strings.LastIndexAny(prefix, ".["); i >= 0 {
+		if prefix[i] == '[' {
+			return items, nil
+		}
+		parent = prefix[:i]
+	}
+
+	props := root
+	if parent != "" {
+		segments, err := fieldpath.Parse(parent)
+		if err != nil {
+			return items, nil
+		}
+		schemas, err := rules.ResolveFieldPath(root, parent)
+		if err != nil || len(schemas) <= len(segments) {
+			return items, nil
+		}
+		props = schemas[len(schemas)-1]
+	}
+
+	names := make([]string, 0, len(props.Properties))
+	for name := range props.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := props.Properties[name]
+		item := completionItem{Label: name, Kind: completionKindField, Detail: prop.Type}
+		if prop.Description != "" {
+			item.Documentation = &markupContent{Kind: markupKindMarkdown, Value: prop.Description}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// fieldPathPrefix returns the part of the field path in front of pos. The
+// field path key must be the only key in the line of pos.
+func fieldPathPrefix(text string, pos Position) (string, bool) {
+	lines := strings.Split(text, "\n")
+	if pos.Line >= len(lines) {
+		return "", false
+	}
+	line := strings.TrimRight(lines[pos.Line], "\r")
+	col, ok := runeColumn(line, pos.Character)
+	if !ok {
+		return "", false
+	}
+	before := string([]rune(line)[:col])
+	i := strings.Index(before, ":")
+	if i < 0 {
+		return "", false
+	}
+	return strings.Trim(before[i+1:], " \t\"'"), true
+}
+
+// uriToPath returns the file path of a file URI.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", errors.Errorf(errFmtUnsupportedURI, uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
@@ -0,0 +1,339 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	fmtLog "github.com/go-log/log/fmt"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	configMapA = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"
+	configMapB = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n"
+)
+
+// fakeLinter reports an issue of each of its rules for every entry and sends
+// its name to runs when it runs.
+type fakeLinter struct {
+	name  string
+	rules []string
+	runs  chan<- string
+}
+
+func (l *fakeLinter) Lint(_ context.Context, pkg *xpkg.Package) (lint.LinterReport, error) {
+	r := lint.LinterReport{}
+	for i := range pkg.Entries {
+		for _, rule := range l.rules {
+			r.Issues = append(r.Issues, lint.Issue{RuleName: rule, Entry: &pkg.Entries[i], Description: l.name})
+		}
+	}
+	l.runs <- l.name
+	return r, nil
+}
+
+// step of a session. Sends the notification method with params or waits
+// for the linter waitRun to run.
+type step struct {
+	method  string
+	params  any
+	waitRun string
+}
+
+func didOpen(name, text string) step {
+	return step{method: "textDocument/didOpen", params: didOpenTextDocumentParams{TextDocument: textDocumentItem{URI: "file:///pkg/" + name, Text: text}}}
+}
+
+func didChange(name, text string) step {
+	return step{method: "textDocument/didChange", params: didChangeTextDocumentParams{
+		TextDocument:   textDocumentIdentifier{URI: "file:///pkg/" + name},
+		ContentChanges: []textDocumentContentChangeEvent{{Text: text}},
+	}}
+}
+
+func didSave(name string) step {
+	return step{method: "textDocument/didSave", params: didSaveTextDocumentParams{TextDocument: textDocumentIdentifier{URI: "file:///pkg/" + name}}}
+}
+
+func didClose(name string) step {
+	return step{method: "textDocument/didClose", params: didCloseTextDocumentParams{TextDocument: textDocumentIdentifier{URI: "file:///pkg/" + name}}}
+}
+
+func waitRun(name string) step {
+	return step{waitRun: name}
+}
+
+func TestServerLint(t *testing.T) {
+	cases := map[string]struct {
+		reason          string
+		lintDelay       time.Duration
+		steps           []step
+		wantRuns        []string
+		wantDiagnostics map[string][]string
+		wantEntries     map[string]string
+	}{
+		"Open": {
+			reason:          "Opened documents are linted with plugins.",
+			steps:           []step{didOpen("a.yaml", configMapA), waitRun("full")},
+			wantRuns:        []string{"full"},
+			wantDiagnostics: map[string][]string{"a.yaml": {"check: full", "plugin.test: full"}},
+		},
+		"ChangesAreDebounced": {
+			reason:    "Changes in quick succession are linted once without plugins. Plugin issues of changed documents are dropped.",
+			lintDelay: 50 * time.Millisecond,
+			steps: []step{
+				didOpen("a.yaml", configMapA), waitRun("full"),
+				didChange("a.yaml", configMapB+"#1\n"), didChange("a.yaml", configMapB+"#2\n"), didChange("a.yaml", configMapB), waitRun("type"),
+			},
+			wantRuns:        []string{"full", "type"},
+			wantDiagnostics: map[string][]string{"a.yaml": {"check: type"}},
+		},
+		"PluginIssuesOfUnchangedDocuments": {
+			reason:    "Plugin issues of the last full run are kept for unchanged documents.",
+			lintDelay: time.Millisecond,
+			steps: []step{
+				didOpen("a.yaml", configMapA), waitRun("full"),
+				didOpen("b.yaml", configMapB), waitRun("full"),
+				didChange("a.yaml", configMapB), waitRun("type"),
+			},
+			wantRuns: []string{"full", "full", "type"},
+			wantDiagnostics: map[string][]string{
+				"a.yaml": {"check: type"},
+				"b.yaml": {"check: type", "plugin.test: full"},
+			},
+		},
+		"Save": {
+			reason:    "Saved documents are linted with plugins right away.",
+			lintDelay: time.Hour,
+			steps: []step{
+				didOpen("a.yaml", configMapA), waitRun("full"),
+				didChange("a.yaml", configMapB), didSave("a.yaml"), waitRun("full"),
+			},
+			wantRuns:        []string{"full", "full"},
+			wantDiagnostics: map[string][]string{"a.yaml": {"check: full", "plugin.test: full"}},
+		},
+		"CloseWithoutSaving": {
+			reason:    "Documents closed without saving get the content of their file again.",
+			lintDelay: time.Hour,
+			steps: []step{
+				didOpen("a.yaml", configMapA), waitRun("full"),
+				didChange("a.yaml", configMapB), didClose("a.yaml"), waitRun("full"),
+			},
+			wantRuns:        []string{"full", "full"},
+			wantDiagnostics: map[string][]string{"a.yaml": {}},
+			wantEntries:     map[string]string{"/pkg/a.yaml": configMapA, "/pkg/b.yaml": configMapB},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for path, content := range map[string]string{"/pkg/a.yaml": configMapA, "/pkg/b.yaml": configMapB} {
+				if err := afero.WriteFile(fs, path, []byte(content), 0o600); err != nil {
+					t.Fatalf("WriteFile(...): unexpected error: %v", err)
+				}
+			}
+			parser := parse.NewPackageDirectoryParser(fs)
+			pkg, err := parser.ParsePackage("/pkg")
+			if err != nil {
+				t.Fatalf("ParsePackage(...): unexpected error: %v", err)
+			}
+			runs := make(chan string, 100)
+			s := NewServer(pkg, parser, lintschema.NewSchemaStore(),
+				&fakeLinter{name: "full", rules: []string{"check", "plugin.test"}, runs: runs},
+				&fakeLinter{name: "type", rules: []string{"check"}, runs: runs},
+				fmtLog.NewFromWriter(io.Discard))
+			if tc.lintDelay > 0 {
+				s.lintDelay = tc.lintDelay
+			}
+
+			session := runSession(t, s, tc.steps, runs)
+
+			// Runs that were not waited for are still in runs.
+			gotRuns := session.runs
+			for len(runs) > 0 {
+				gotRuns = append(gotRuns, <-runs)
+			}
+			if !reflect.DeepEqual(tc.wantRuns, gotRuns) {
+				t.Errorf("\n%s\nServe(...): want runs %v, got %v", tc.reason, tc.wantRuns, gotRuns)
+			}
+			if !reflect.DeepEqual(tc.wantDiagnostics, session.last) {
+				t.Errorf("\n%s\nServe(...): want diagnostics %v, got %v", tc.reason, tc.wantDiagnostics, session.last)
+			}
+			if tc.wantEntries != nil {
+				gotEntries := map[string]string{}
+				for _, e := range pkg.Entries {
+					gotEntries[e.Source] = e.Raw
+				}
+				if !reflect.DeepEqual(tc.wantEntries, gotEntries) {
+					t.Errorf("\n%s\nServe(...): want entries %q, got %q", tc.reason, tc.wantEntries, gotEntries)
+				}
+			}
+		})
+	}
+}
+
+// sessionResult are the linter runs waited for and the last diagnostics
+// published per document name formatted as "<code>: <message>".
+type sessionResult struct {
+	runs []string
+	last map[string][]string
+}
+
+// runSession serves the steps followed by shutdown and exit with s.
+func runSession(t *testing.T, s *Server, steps []step, runs <-chan string) sessionResult {
+	t.Helper()
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	result := sessionResult{last: map[string][]string{}}
+	mu := sync.Mutex{}
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		c := newConn(clientIn, nil)
+		for {
+			raw, err := c.read()
+			if err != nil {
+				return
+			}
+			n := struct {
+				Method string                   `json:"method"`
+				Params publishDiagnosticsParams `json:"params"`
+			}{}
+			if err := json.Unmarshal(raw, &n); err != nil || n.Method != "textDocument/publishDiagnostics" {
+				continue
+			}
+			got := []string{}
+			for _, d := range n.Params.Diagnostics {
+				got = append(got, fmt.Sprintf("%s: %s", d.Code, d.Message))
+			}
+			sort.Strings(got)
+			mu.Lock()
+			result.last[n.Params.URI[len("file:///pkg/"):]] = got
+			mu.Unlock()
+		}
+	}()
+
+	client := newConn(nil, clientOut)
+	send := func(msg any) {
+		if err := client.write(msg); err != nil {
+			t.Fatalf("write(...): unexpected error: %v", err)
+		}
+	}
+	for _, st := range steps {
+		if st.waitRun != "" {
+			select {
+			case got := <-runs:
+				result.runs = append(result.runs, got)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Serve(...): linter %s did not run", st.waitRun)
+			}
+			continue
+		}
+		send(notification{JSONRPC: jsonRPCVersion, Method: st.method, Params: st.params})
+	}
+	id := json.RawMessage("1")
+	send(request{JSONRPC: jsonRPCVersion, ID: &id, Method: "shutdown"})
+	send(request{JSONRPC: jsonRPCVersion, Method: "exit"})
+	if err := <-served; err != nil && !errors.Is(err, io.EOF) {
+		t.Fatalf("Serve(...): unexpected error: %v", err)
+	}
+	clientOut.Close()
+	<-read
+	mu.Lock()
+	defer mu.Unlock()
+	return result
+}
+
+func TestNodeRange(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		text   string
+		want   Range
+	}{
+		"ASCII": {
+			reason: "Columns of ASCII text are unchanged.",
+			text:   "name: value\n",
+			want:   Range{Start: Position{Character: 6}, End: Position{Character: 11}},
+		},
+		"BasicMultilingualPlane": {
+			reason: "Runes of the basic multilingual plane are a single UTF-16 code unit.",
+			text:   "é: value\n",
+			want:   Range{Start: Position{Character: 3}, End: Position{Character: 8}},
+		},
+		"SurrogatePair": {
+			reason: "Runes outside of the basic multilingual plane are two UTF-16 code units.",
+			text:   "😀: v😀\n",
+			want:   Range{Start: Position{Character: 4}, End: Position{Character: 7}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			root := &yaml.Node{}
+			if err := yaml.Unmarshal([]byte(tc.text), root); err != nil {
+				t.Fatalf("yaml.Unmarshal(...): unexpected error: %v", err)
+			}
+			value := documentContent(root).Content[1]
+			if got := nodeRange(value, tc.text); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nnodeRange(...): want %+v, got %+v", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFieldPathPrefix(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		text   string
+		pos    Position
+		want   string
+		wantOK bool
+	}{
+		"ASCII": {
+			reason: "The field path in front of the cursor is returned.",
+			text:   "fromFieldPath: spec.name",
+			pos:    Position{Character: 20},
+			want:   "spec.",
+			wantOK: true,
+		},
+		"SurrogatePair": {
+			reason: "The cursor position counts UTF-16 code units.",
+			text:   "fromFieldPath: metadata.labels[😀].x",
+			pos:    Position{Character: 35},
+			want:   "metadata.labels[😀].",
+			wantOK: true,
+		},
+		"BeyondLine": {
+			reason: "Positions after the end of the line are rejected.",
+			text:   "fromFieldPath: spec",
+			pos:    Position{Character: 30},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := fieldPathPrefix(tc.text, tc.pos)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("\n%s\nfieldPathPrefix(...): want %q, %t, got %q, %t", tc.reason, tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of fields that contain a field path.
+const (
+	keyFromFieldPath      = "fromFieldPath"
+	keyToFieldPath        = "toFieldPath"
+	keyValueFromFieldPath = "valueFromFieldPath"
+)
+
+// nodePath is the path to a YAML node. Segments are either mapping keys
+// (string) or sequence indices (int).
+type nodePath []any
+
+// findFieldPathAt returns the value node of the field path key in line
+// (1-based) and the path to it.
+func findFieldPathAt(root *yaml.Node, line int) (*yaml.Node, nodePath) {
+	return findFieldPathIn(documentContent(root), line, nodePath{})
+}
+
+func findFieldPathIn(node *yaml.Node, line int, path nodePath) (*yaml.Node, nodePath) {
+	if node == nil || node.Line > line {
+		return nil, nil
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := append(append(nodePath{}, path...), key.Value)
+			if key.Line == line && isFieldPathKey(key.Value) && value.Kind == yaml.ScalarNode {
+				return value, keyPath
+			}
+			if found, foundPath := findFieldPathIn(value, line, keyPath); found != nil {
+				return found, foundPath
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if found, foundPath := findFieldPathIn(item, line, append(append(nodePath{}, path...), i)); found != nil {
+				return found, foundPath
+			}
+		}
+	}
+	return nil, nil
+}
+
+func isFieldPathKey(key string) bool {
+	return key == keyFromFieldPath || key == keyToFieldPath || key == keyValueFromFieldPath
+}
+
+// documentContent returns the root content of a document node.
+func documentContent(root *yaml.Node) *yaml.Node {
+	if root != nil && root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		return root.Content[0]
+	}
+	return root
+}
+
+// nodeAt returns the node at path in root or nil if it does not exist.
+func nodeAt(root *yaml.Node, path nodePath) *yaml.Node {
+	current := documentContent(root)
+	for _, segment := range path {
+		if current == nil {
+			return nil
+		}
+		switch s := segment.(type) {
+		case string:
+			current = mappingValue(current, s)
+		case int:
+			if current.Kind != yaml.SequenceNode || s >= len(current.Content) {
+				return nil
+			}
+			current = current.Content[s]
+		}
+	}
+	return current
+}
+
+// mappingValue returns the value of key in node or nil if node is no mapping
+// or has no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingString returns the scalar value of key in node or an empty string.
+func mappingString(node *yaml.Node, key string) string {
+	value := mappingValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return value.Value
+}
+
+// nodeRange returns the range of node within text. Multi-line nodes span to
+// the end of their first line.
+func nodeRange(node *yaml.Node, text string) Range {
+	// Columns of nodes count runes.
+	line, start := node.Line-1, node.Column-1
+	if line < 0 || start < 0 {
+		return Range{}
+	}
+	lineText := ""
+	if lines := strings.Split(text, "\n"); line < len(lines) {
+		lineText = strings.TrimRight(lines[line], "\r")
+	}
+	lineLength := utf8.RuneCountInString(lineText)
+	end := lineLength
+	if node.Kind == yaml.ScalarNode && !strings.Contains(node.Value, "\n") {
+		length := utf8.RuneCountInString(node.Value)
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			length += 2
+		}
+		if start+length < lineLength {
+			end = start + length
+		}
+	}
+	return Range{
+		Start: Position{Line: line, Character: utf16Column(lineText, start)},
+		End:   Position{Line: line, Character: utf16Column(lineText, end)},
+	}
+}
+
+// utf16Column converts the column col of line from runes to UTF-16 code
+// units, the unit of Position.Character.
+func utf16Column(line string, col int) int {
+	units := 0
+	for _, r := range line {
+		if col == 0 {
+			break
+		}
+		units += utf16Length(r)
+		col--
+	}
+	return units + col
+}
+
+// runeColumn converts the column character of line from UTF-16 code units to
+// runes. Returns false if line is shorter than character.
+func runeColumn(line string, character int) (int, bool) {
+	col := 0
+	for _, r := range line {
+		if character <= 0 {
+			break
+		}
+		character -= utf16Length(r)
+		col++
+	}
+	return col, character <= 0
+}
+
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		// Encoded as surrogate pair.
+		return 2
+	}
+	return 1
+}
+
+// contains determines if r contains pos.
+func (r Range) contains(pos Position) bool {
+	if pos.Line < r.Start.Line || pos.Line > r.End.Line {
+		return false
+	}
+	if pos.Line == r.Start.Line && pos.Character < r.Start.Character {
+		return false
+	}
+	if pos.Line == r.End.Line && pos.Character > r.End.Character {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,278 @@
+package xpkg
+
+import (
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+// Composition modes.
+const (
+	CompositionModeResources = "Resources"
+	CompositionModePipeline  = "Pipeline"
+)
+
+// Function type of the function based compositions introduced with Crossplane
+// v1.11.
+const (
+	FunctionTypeContainer = "Container"
+)
+
+// Patch types of environment patches introduced with Crossplane v1.11.
+const (
+	PatchTypeFromEnvironmentFieldPath xpv1.PatchType = "FromEnvironmentFieldPath"
+	PatchTypeToEnvironmentFieldPath   xpv1.PatchType = "ToEnvironmentFieldPath"
+	PatchTypeCombineFromEnvironment   xpv1.PatchType = "CombineFromEnvironment"
+	PatchTypeCombineToEnvironment     xpv1.PatchType = "CombineToEnvironment"
+)
+
+// Types of environment sources and label selectors.
+const (
+	EnvironmentSourceTypeReference = "Reference"
+	EnvironmentSourceTypeSelector  = "Selector"
+
+	EnvironmentSelectorLabelTypeFromCompositeFieldPath = "FromCompositeFieldPath"
+	EnvironmentSelectorLabelTypeValue                  = "Value"
+)
+
+var (
+	// PatchAndTransformInputGroupKind is the kind of input of
+	// function-patch-and-transform.
+	PatchAndTransformInputGroupKind = schema.GroupKind{Group: "pt.fn.crossplane.io", Kind: "Resources"}
+
+	functionGroupKind          = schema.GroupKind{Group: "pkg.crossplane.io", Kind: "Function"}
+	functionPackageGroupKind   = schema.GroupKind{Group: "meta.pkg.crossplane.io", Kind: "Function"}
+	environmentConfigGroupKind = schema.GroupKind{Group: "apiextensions.crossplane.io", Kind: "EnvironmentConfig"}
+)
+
+// CompositionExtensions contains the fields of a Composition that are not part
+// of the Composition API vendored by this module.
+type CompositionExtensions struct {
+	Spec CompositionExtensionsSpec `json:"spec"`
+}
+
+// CompositionExtensionsSpec contains the spec fields of a Composition that are
+// not part of the vendored Composition API.
+type CompositionExtensionsSpec struct {
+	// Mode of the composition. Either Resources or Pipeline.
+	Mode *string `json:"mode,omitempty"`
+
+	// Pipeline of functions used in Pipeline mode.
+	Pipeline []PipelineStep `json:"pipeline,omitempty"`
+
+	// Functions used by the alpha composition functions of Crossplane v1.11
+	// to v1.13.
+	Functions []ContainerFunction `json:"functions,omitempty"`
+
+	// Environment configures the in-memory environment of a composition.
+	Environment *EnvironmentConfiguration `json:"environment,omitempty"`
+}
+
+// EnvironmentConfiguration selects the EnvironmentConfigs that make up the
+// environment of a composite resource.
+type EnvironmentConfiguration struct {
+	// EnvironmentConfigs selects the EnvironmentConfigs that are merged into
+	// the environment.
+	EnvironmentConfigs []EnvironmentSource `json:"environmentConfigs,omitempty"`
+
+	// Patches between the composite resource and the environment.
+	Patches []xpv1.Patch `json:"patches,omitempty"`
+}
+
+// EnvironmentSource selects one or more EnvironmentConfigs.
+type EnvironmentSource struct {
+	// Type of the source. Either Reference or Selector.
+	Type string `json:"type,omitempty"`
+
+	// Ref references an EnvironmentConfig by name.
+	Ref *EnvironmentSourceReference `json:"ref,omitempty"`
+
+	// Selector selects EnvironmentConfigs by label.
+	Selector *EnvironmentSourceSelector `json:"selector,omitempty"`
+}
+
+// EnvironmentSourceReference references an EnvironmentConfig by name.
+type EnvironmentSourceReference struct {
+	Name string `json:"name"`
+}
+
+// EnvironmentSourceSelector selects EnvironmentConfigs by label.
+type EnvironmentSourceSelector struct {
+	MatchLabels []EnvironmentSourceSelectorLabelMatcher `json:"matchLabels,omitempty"`
+}
+
+// EnvironmentSourceSelectorLabelMatcher matches a single label.
+type EnvironmentSourceSelectorLabelMatcher struct {
+	// Key of the label.
+	Key string `json:"key"`
+
+	// Type of the matcher. Either FromCompositeFieldPath or Value.
+	Type string `json:"type,omitempty"`
+
+	// ValueFromFieldPath is the composite field path of the label value.
+	ValueFromFieldPath *string `json:"valueFromFieldPath,omitempty"`
+
+	// Value of the label.
+	Value *string `json:"value,omitempty"`
+}
+
+// PipelineStep is a step of a composition function pipeline.
+type PipelineStep struct {
+	// Step name. Must be unique within its pipeline.
+	Step string `json:"step"`
+
+	// FunctionRef references the function to run.
+	FunctionRef FunctionReference `json:"functionRef"`
+
+	// Input of the function.
+	Input *runtime.RawExtension `json:"input,omitempty"`
+}
+
+// FunctionReference references a Function.
+type FunctionReference struct {
+	Name string `json:"name"`
+}
+
+// ContainerFunction is an alpha composition function that runs as container.
+type ContainerFunction struct {
+	// Name of the function. Must be unique within its composition.
+	Name string `json:"name"`
+
+	// Type of the function.
+	Type string `json:"type"`
+
+	// Container configuration of the function.
+	Container *ContainerFunctionConfig `json:"container,omitempty"`
+}
+
+// ContainerFunctionConfig configures the container of a ContainerFunction.
+type ContainerFunctionConfig struct {
+	Image string `json:"image"`
+}
+
+// PatchAndTransformInput is the input of function-patch-and-transform.
+type PatchAndTransformInput struct {
+	// PatchSets define a named set of patches that may be included by any
+	// resource.
+	PatchSets []xpv1.PatchSet `json:"patchSets,omitempty"`
+
+	// Resources is a list of resource templates.
+	Resources []xpv1.ComposedTemplate `json:"resources,omitempty"`
+}
+
+// AsCompositionExtensions returns the fields of the Composition e that are not
+// part of the vendored Composition API.
+func (e *PackageEntry) AsCompositionExtensions() (*CompositionExtensions, error) {
+	if !e.IsComposition() {
+		return nil, errors.Errorf(errPackageObjectNotType, "Composition")
+	}
+	ext := &CompositionExtensions{}
+	if err := yaml.Unmarshal([]byte(e.Raw), ext); err != nil {
+		return nil, errors.Wrapf(err, errPackageObjectConvert, "CompositionExtensions")
+	}
+	return ext, nil
+}
+
+// IsPatchAndTransformInput determines if s has an input for
+// function-patch-and-transform.
+func (s *PipelineStep) IsPatchAndTransformInput() bool {
+	if s.Input == nil {
+		return false
+	}
+	meta := &runtime.TypeMeta{}
+	if err := yaml.Unmarshal(s.Input.Raw, meta); err != nil {
+		return false
+	}
+	return meta.GroupVersionKind().GroupKind() == PatchAndTransformInputGroupKind
+}
+
+// AsPatchAndTransformInput returns the input of s as PatchAndTransformInput.
+func (s *PipelineStep) AsPatchAndTransformInput() (*PatchAndTransformInput, error) {
+	input := &PatchAndTransformInput{}
+	if s.Input == nil {
+		return input, nil
+	}
+	if err := yaml.Unmarshal(s.Input.Raw, input); err != nil {
+		return nil, errors.Wrapf(err, errPackageObjectConvert, "PatchAndTransformInput")
+	}
+	return input, nil
+}
+
+// IsFunction determines if e is either a Function or a Function package
+// descriptor.
+func (e *PackageEntry) IsFunction() bool {
+	gk := e.Object.GroupVersionKind().GroupKind()
+	return gk == functionGroupKind || gk == functionPackageGroupKind
+}
+
+// IsEnvironmentConfig determines if e is an EnvironmentConfig.
+func (e *PackageEntry) IsEnvironmentConfig() bool {
+	return e.Object.GroupVersionKind().GroupKind() == environmentConfigGroupKind
+}
+
+// EmbeddedManifestFields are the fields of composed resources that embed the
+// manifest of another resource by kind. Their schema allows any object.
+var EmbeddedManifestFields = map[schema.GroupKind][]string{
+	{Group: "kubernetes.crossplane.io", Kind: "Object"}: {"spec", "forProvider", "manifest"},
+}
+
+// GetEmbeddedManifest returns the manifest embedded in the composed resource
+// base and the fields that hold it. Returns nil if base does not embed a
+// manifest.
+func GetEmbeddedManifest(base *unstructured.Unstructured) ([]string, map[string]interface{}) {
+	fields, exists := EmbeddedManifestFields[base.GroupVersionKind().GroupKind()]
+	if !exists {
+		return nil, nil
+	}
+	obj, found, err := unstructured.NestedMap(base.Object, fields...)
+	if err != nil || !found {
+		return nil, nil
+	}
+	return fields, obj
+}
+
+// ComposedTemplates are the resource templates and patch sets of a
+// Composition or of the input of a function-patch-and-transform pipeline
+// step.
+type ComposedTemplates struct {
+	Resources []xpv1.ComposedTemplate
+	PatchSets []xpv1.PatchSet
+
+	// Path of the object that holds the resources and patchSets fields.
+	Path jsonpath.JSONPath
+}
+
+// GetComposedTemplates returns the ComposedTemplates of the Composition comp
+// of e followed by those of its function-patch-and-transform pipeline steps.
+// Steps with invalid input are skipped.
+func GetComposedTemplates(e *PackageEntry, comp *xpv1.Composition) []ComposedTemplates {
+	templates := []ComposedTemplates{{
+		Resources: comp.Spec.Resources,
+		PatchSets: comp.Spec.PatchSets,
+		Path:      jsonpath.NewJSONPath("spec"),
+	}}
+	ext, err := e.AsCompositionExtensions()
+	if err != nil {
+		return templates
+	}
+	for i, step := range ext.Spec.Pipeline {
+		if !step.IsPatchAndTransformInput() {
+			continue
+		}
+		input, err := step.AsPatchAndTransformInput()
+		if err != nil {
+			continue
+		}
+		templates = append(templates, ComposedTemplates{
+			Resources: input.Resources,
+			PatchSets: input.PatchSets,
+			Path:      jsonpath.NewJSONPath("spec", "pipeline", i, "input"),
+		})
+	}
+	return templates
+}
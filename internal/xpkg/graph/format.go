@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Formats of exported graphs.
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatJSON    = "json"
+)
+
+const errFmtUnknownFormat = "unknown graph format '%s', expected '%s', '%s' or '%s'"
+
+// Write g to w in format.
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatDOT:
+		return g.WriteDOT(w)
+	case FormatMermaid:
+		return g.WriteMermaid(w)
+	case FormatJSON:
+		return g.WriteJSON(w)
+	}
+	return errors.Errorf(errFmtUnknownFormat, format, FormatDOT, FormatMermaid, FormatJSON)
+}
+
+// dotShapes are the DOT node shapes by node type.
+var dotShapes = map[NodeType]string{
+	NodeTypeXRD:         "box, style=bold",
+	NodeTypeComposition: "ellipse",
+	NodeTypeKind:        "box, style=rounded",
+	NodeTypePackage:     "folder",
+}
+
+// WriteDOT writes g as Graphviz DOT graph to w.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph crossplane {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(b, "  %s [label=%s, shape=%s];\n", strconv.Quote(n.ID), strconv.Quote(n.Label), dotShapes[n.Type])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(b, "  %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(string(e.Type)))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidShapes are the opening and closing brackets of Mermaid nodes by
+// node type.
+var mermaidShapes = map[NodeType][2]string{
+	NodeTypeXRD:         {"[[", "]]"},
+	NodeTypeComposition: {"(", ")"},
+	NodeTypeKind:        {"[", "]"},
+	NodeTypePackage:     {"[(", ")]"},
+}
+
+// WriteMermaid writes g as Mermaid flowchart to w. Nodes are identified by
+// their index, as Mermaid IDs cannot contain most characters of node IDs.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	ids := make(map[string]string, len(g.Nodes))
+	b := &strings.Builder{}
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		shape := mermaidShapes[n.Type]
+		label := strings.ReplaceAll(n.Label, `"`, "#quot;")
+		fmt.Fprintf(b, "  %s%s\"%s\"%s\n", ids[n.ID], shape[0], label, shape[1])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(b, "  %s -->|%s| %s\n", ids[e.From], e.Type, ids[e.To])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes g as JSON object with nodes and edges to w.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
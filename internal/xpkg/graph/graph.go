@@ -0,0 +1,210 @@
+// Package graph builds the dependency graph of the APIs of a package: XRDs,
+// the compositions implementing them, the kinds they compose and the packages
+// providing these kinds.
+package graph
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+// NodeType is the type of a Node.
+type NodeType string
+
+const (
+	// NodeTypeXRD is a CompositeResourceDefinition of the package.
+	NodeTypeXRD NodeType = "XRD"
+
+	// NodeTypeComposition is a Composition of the package.
+	NodeTypeComposition NodeType = "Composition"
+
+	// NodeTypeKind is a kind that is not defined by an XRD of the package,
+	// for example a managed resource.
+	NodeTypeKind NodeType = "Kind"
+
+	// NodeTypePackage is a package that provides kinds.
+	NodeTypePackage NodeType = "Package"
+)
+
+// EdgeType is the type of an Edge.
+type EdgeType string
+
+const (
+	// EdgeTypeImplementedBy points from an XRD or kind to a Composition that
+	// implements it.
+	EdgeTypeImplementedBy EdgeType = "implementedBy"
+
+	// EdgeTypeComposes points from a Composition to a kind or XRD it
+	// composes.
+	EdgeTypeComposes EdgeType = "composes"
+
+	// EdgeTypeEmbeds points from a Composition to a kind it composes as
+	// manifest embedded in another resource, such as a provider-kubernetes
+	// Object.
+	EdgeTypeEmbeds EdgeType = "embeds"
+
+	// EdgeTypeProvidedBy points from a kind to the package providing it.
+	EdgeTypeProvidedBy EdgeType = "providedBy"
+)
+
+// Node of a Graph.
+type Node struct {
+	ID    string   `json:"id"`
+	Type  NodeType `json:"type"`
+	Label string   `json:"label"`
+
+	// Source is the file that defines the node. Empty for kinds and
+	// packages.
+	Source string `json:"source,omitempty"`
+}
+
+// Edge of a Graph.
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Type EdgeType `json:"type"`
+}
+
+// Graph of the APIs of a package. Nodes and edges are sorted by ID.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+type builder struct {
+	pkg     *xpkg.Package
+	origins lint.SchemaOrigins
+	nodes   map[string]Node
+	edges   map[Edge]struct{}
+
+	// xrds are the IDs of the XRD nodes by the group kinds of their
+	// composite resources and claims.
+	xrds map[schema.GroupKind]string
+}
+
+// Build the graph of pkg. The packages providing composed kinds are looked
+// up in origins, if it is not nil. Kinds defined by pkg itself are not linked
+// to a package.
+func Build(pkg *xpkg.Package, origins lint.SchemaOrigins) *Graph {
+	b := &builder{
+		pkg:     pkg,
+		origins: origins,
+		nodes:   map[string]Node{},
+		edges:   map[Edge]struct{}{},
+		xrds:    map[schema.GroupKind]string{},
+	}
+	for i := range pkg.Entries {
+		if e := &pkg.Entries[i]; e.IsXRD() {
+			b.addXRD(e)
+		}
+	}
+	for i := range pkg.Entries {
+		if e := &pkg.Entries[i]; e.IsComposition() {
+			b.addComposition(e)
+		}
+	}
+	return b.graph()
+}
+
+func (b *builder) addXRD(e *xpkg.PackageEntry) {
+	xrd, err := e.AsXRD()
+	if err != nil {
+		return
+	}
+	id := "xrd:" + xrd.GetName()
+	b.nodes[id] = Node{ID: id, Type: NodeTypeXRD, Label: xrd.Spec.Names.Kind, Source: e.Source}
+	b.xrds[schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.Names.Kind}] = id
+	if xrd.Spec.ClaimNames != nil {
+		b.xrds[schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.ClaimNames.Kind}] = id
+	}
+}
+
+func (b *builder) addComposition(e *xpkg.PackageEntry) {
+	comp, err := e.AsComposition()
+	if err != nil {
+		return
+	}
+	id := "composition:" + comp.GetName()
+	b.nodes[id] = Node{ID: id, Type: NodeTypeComposition, Label: comp.GetName(), Source: e.Source}
+
+	compositeGv, err := schema.ParseGroupVersion(comp.Spec.CompositeTypeRef.APIVersion)
+	if err == nil && comp.Spec.CompositeTypeRef.APIVersion != "" && comp.Spec.CompositeTypeRef.Kind != "" {
+		compositeID := b.addKind(compositeGv.WithKind(comp.Spec.CompositeTypeRef.Kind))
+		b.addEdge(compositeID, id, EdgeTypeImplementedBy)
+	}
+
+	for _, t := range xpkg.GetComposedTemplates(e, comp) {
+		for _, r := range t.Resources {
+			base := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal(r.Base.Raw, base); err != nil || base.GetAPIVersion() == "" || base.GetKind() == "" {
+				continue
+			}
+			b.addEdge(id, b.addKind(base.GroupVersionKind()), EdgeTypeComposes)
+			if _, manifest := xpkg.GetEmbeddedManifest(base); manifest != nil {
+				embedded := &unstructured.Unstructured{Object: manifest}
+				if embedded.GetAPIVersion() != "" && embedded.GetKind() != "" {
+					b.addEdge(id, b.addKind(embedded.GroupVersionKind()), EdgeTypeEmbeds)
+				}
+			}
+		}
+	}
+}
+
+// addKind adds the node of gvk and the package providing it. Returns the ID
+// of the XRD node if gvk is defined by an XRD of the package.
+func (b *builder) addKind(gvk schema.GroupVersionKind) string {
+	if id, exists := b.xrds[gvk.GroupKind()]; exists {
+		return id
+	}
+	id := "kind:" + gvk.GroupKind().String()
+	b.nodes[id] = Node{ID: id, Type: NodeTypeKind, Label: gvk.GroupKind().String()}
+
+	if b.origins == nil {
+		return id
+	}
+	origin := b.origins.GetSchemaOrigin(gvk)
+	if origin == nil || origin.Package == "" || origin.Package == b.pkg.Source {
+		return id
+	}
+	pkgID := "package:" + origin.Package
+	b.nodes[pkgID] = Node{ID: pkgID, Type: NodeTypePackage, Label: origin.Package}
+	b.addEdge(id, pkgID, EdgeTypeProvidedBy)
+	return id
+}
+
+func (b *builder) addEdge(from, to string, t EdgeType) {
+	b.edges[Edge{From: from, To: to, Type: t}] = struct{}{}
+}
+
+func (b *builder) graph() *Graph {
+	g := &Graph{
+		Nodes: make([]Node, 0, len(b.nodes)),
+		Edges: make([]Edge, 0, len(b.edges)),
+	}
+	for _, n := range b.nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	for e := range b.edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		x, y := g.Edges[i], g.Edges[j]
+		if x.From != y.From {
+			return x.From < y.From
+		}
+		if x.To != y.To {
+			return x.To < y.To
+		}
+		return x.Type < y.Type
+	})
+	return g
+}
@@ -0,0 +1,128 @@
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const (
+	xrd = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xnetworks.example.org
+spec:
+  group: example.org
+  names:
+    kind: XNetwork
+    plural: xnetworks
+  claimNames:
+    kind: Network
+    plural: networks
+`
+	composition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: cluster
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XCluster
+  resources:
+  - name: network
+    base:
+      apiVersion: example.org/v1
+      kind: XNetwork
+  - name: cluster
+    base:
+      apiVersion: eks.aws.upbound.io/v1beta1
+      kind: Cluster
+  - name: config
+    base:
+      apiVersion: kubernetes.crossplane.io/v1alpha1
+      kind: Object
+      spec:
+        forProvider:
+          manifest:
+            apiVersion: v1
+            kind: ConfigMap
+  - name: incomplete
+    base:
+      kind: Bucket
+`
+)
+
+// fakeOrigins defines kinds in packages by group kind.
+type fakeOrigins map[schema.GroupKind]string
+
+func (o fakeOrigins) GetSchemaOrigin(gvk schema.GroupVersionKind) *lint.SchemaOrigin {
+	pkg, exists := o[gvk.GroupKind()]
+	if !exists {
+		return nil
+	}
+	return &lint.SchemaOrigin{Package: pkg}
+}
+
+func TestBuild(t *testing.T) {
+	pkg := &xpkg.Package{Source: "pkg"}
+	for i, m := range []string{xrd, composition} {
+		e, err := parse.ParsePackageEntry([]byte(m), fmt.Sprintf("%d.yaml", i))
+		if err != nil {
+			t.Fatalf("ParsePackageEntry(...): unexpected error: %v", err)
+		}
+		pkg.Entries = append(pkg.Entries, e)
+	}
+	edges := []Edge{
+		{From: "composition:cluster", To: "kind:Cluster.eks.aws.upbound.io", Type: EdgeTypeComposes},
+		{From: "composition:cluster", To: "kind:ConfigMap", Type: EdgeTypeEmbeds},
+		{From: "composition:cluster", To: "kind:Object.kubernetes.crossplane.io", Type: EdgeTypeComposes},
+		{From: "composition:cluster", To: "xrd:xnetworks.example.org", Type: EdgeTypeComposes},
+		{From: "kind:XCluster.example.org", To: "composition:cluster", Type: EdgeTypeImplementedBy},
+	}
+
+	cases := map[string]struct {
+		reason    string
+		origins   lint.SchemaOrigins
+		wantNodes []string
+		wantEdges []Edge
+	}{
+		"WithoutOrigins": {
+			reason:    "Kinds are not linked to packages without origins. Bases without apiVersion are skipped.",
+			wantNodes: []string{"composition:cluster", "kind:Cluster.eks.aws.upbound.io", "kind:ConfigMap", "kind:Object.kubernetes.crossplane.io", "kind:XCluster.example.org", "xrd:xnetworks.example.org"},
+			wantEdges: edges,
+		},
+		"WithOrigins": {
+			reason: "Kinds defined by other packages are linked to them.",
+			origins: fakeOrigins{
+				{Group: "eks.aws.upbound.io", Kind: "Cluster"}: "provider-aws-eks",
+				{Group: "example.org", Kind: "XCluster"}:       "pkg",
+			},
+			wantNodes: []string{"composition:cluster", "kind:Cluster.eks.aws.upbound.io", "kind:ConfigMap", "kind:Object.kubernetes.crossplane.io", "kind:XCluster.example.org", "package:provider-aws-eks", "xrd:xnetworks.example.org"},
+			wantEdges: append([]Edge{edges[0], edges[1], edges[2], edges[3]},
+				Edge{From: "kind:Cluster.eks.aws.upbound.io", To: "package:provider-aws-eks", Type: EdgeTypeProvidedBy},
+				edges[4],
+			),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := Build(pkg, tc.origins)
+			gotNodes := []string{}
+			for _, n := range g.Nodes {
+				gotNodes = append(gotNodes, n.ID)
+			}
+			if !reflect.DeepEqual(tc.wantNodes, gotNodes) {
+				t.Errorf("\n%s\nBuild(...): want nodes %v, got %v", tc.reason, tc.wantNodes, gotNodes)
+			}
+			if !reflect.DeepEqual(tc.wantEdges, g.Edges) {
+				t.Errorf("\n%s\nBuild(...): want edges %v, got %v", tc.reason, tc.wantEdges, g.Edges)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package baseline records the issues of a package, so that only new issues
+// are reported when the linter is adopted for an existing package.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+const (
+	// Version of the baseline file format.
+	Version = 1
+
+	errReadBaseline       = "failed to read baseline"
+	errParseBaseline      = "failed to parse baseline"
+	errFmtBaselineVersion = "unsupported baseline version %d, expected %d"
+	errMarshalBaseline    = "failed to marshal baseline"
+	errWriteBaseline      = "failed to write baseline"
+)
+
+// Baseline is a set of known issues.
+type Baseline struct {
+	Version int     `json:"version"`
+	Issues  []Entry `json:"issues"`
+}
+
+// Entry is a known issue. Rule, File, Path and Description are informational
+// and only Fingerprint is used to match issues. Line numbers are not part of
+// an entry, so entries stay valid when unrelated lines change.
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	Rule        string `json:"rule"`
+	File        string `json:"file,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Description string `json:"description"`
+}
+
+// New creates a Baseline of the issues of report. Files are recorded relative
+// to root.
+func New(report lint.LinterReport, root string) *Baseline {
+	b := &Baseline{Version: Version, Issues: make([]Entry, len(report.Issues))}
+	for i, issue := range report.Issues {
+		b.Issues[i] = newEntry(issue, root)
+	}
+	sort.SliceStable(b.Issues, func(i, j int) bool {
+		x, y := b.Issues[i], b.Issues[j]
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		if x.Rule != y.Rule {
+			return x.Rule < y.Rule
+		}
+		if x.Path != y.Path {
+			return x.Path < y.Path
+		}
+		return x.Description < y.Description
+	})
+	return b
+}
+
+// Load the Baseline at path.
+func Load(fs afero.Fs, path string) (*Baseline, error) {
+	raw, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadBaseline)
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(raw, b); err != nil {
+		return nil, errors.Wrap(err, errParseBaseline)
+	}
+	if b.Version != Version {
+		return nil, errors.Errorf(errFmtBaselineVersion, b.Version, Version)
+	}
+	return b, nil
+}
+
+// Write b to path.
+func (b *Baseline) Write(fs afero.Fs, path string) error {
+	raw, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errMarshalBaseline)
+	}
+	if err := afero.WriteFile(fs, path, append(raw, '\n'), 0o644); err != nil {
+		return errors.Wrap(err, errWriteBaseline)
+	}
+	return nil
+}
+
+// Filter removes the issues of report that are in b. Returns the remaining
+// issues and the entries of b that no longer occur. An entry matches a single
+// issue, so additional occurrences of a known issue are reported as new.
+func (b *Baseline) Filter(report lint.LinterReport, root string) (lint.LinterReport, []Entry) {
+	known := map[string]int{}
+	for _, e := range b.Issues {
+		known[e.Fingerprint]++
+	}
+	filtered := lint.LinterReport{}
+	for _, issue := range report.Issues {
+		fp := newEntry(issue, root).Fingerprint
+		if known[fp] > 0 {
+			known[fp]--
+			continue
+		}
+		filtered.Issues = append(filtered.Issues, issue)
+	}
+
+	stale := []Entry{}
+	for _, e := range b.Issues {
+		if known[e.Fingerprint] > 0 {
+			known[e.Fingerprint]--
+			stale = append(stale, e)
+		}
+	}
+	return filtered, stale
+}
+
+func newEntry(issue lint.Issue, root string) Entry {
+	e := Entry{
+		Rule:        issue.RuleName,
+		Path:        issue.Path.String(),
+		Description: issue.Description,
+	}
+	if issue.Entry != nil {
+		e.File = relativeFile(issue.Entry.Source, root)
+	}
+	e.Fingerprint = fingerprint(e)
+	return e
+}
+
+// relativeFile returns file relative to root with forward slashes, so
+// baselines can be shared across operating systems and working directories.
+func relativeFile(file, root string) string {
+	if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	return filepath.ToSlash(file)
+}
+
+func fingerprint(e Entry) string {
+	h := sha256.New()
+	for _, s := range []string{e.Rule, e.File, e.Path, e.Description} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,131 @@
+package baseline
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := Entry{Rule: "r", File: "pkg/a.yaml", Path: ".spec", Description: "d"}
+	cases := map[string]struct {
+		reason string
+		other  Entry
+		equal  bool
+	}{
+		"Same": {
+			reason: "Equal entries have the same fingerprint.",
+			other:  base,
+			equal:  true,
+		},
+		"IgnoresFingerprint": {
+			reason: "The fingerprint does not depend on a previous fingerprint.",
+			other:  Entry{Fingerprint: "x", Rule: "r", File: "pkg/a.yaml", Path: ".spec", Description: "d"},
+			equal:  true,
+		},
+		"DifferentRule": {
+			reason: "Issues of different rules differ.",
+			other:  Entry{Rule: "o", File: "pkg/a.yaml", Path: ".spec", Description: "d"},
+		},
+		"DifferentFile": {
+			reason: "Issues of different files differ.",
+			other:  Entry{Rule: "r", File: "pkg/b.yaml", Path: ".spec", Description: "d"},
+		},
+		"DifferentPath": {
+			reason: "Issues of different paths differ.",
+			other:  Entry{Rule: "r", File: "pkg/a.yaml", Path: ".metadata", Description: "d"},
+		},
+		"DifferentDescription": {
+			reason: "Issues with different descriptions differ.",
+			other:  Entry{Rule: "r", File: "pkg/a.yaml", Path: ".spec", Description: "o"},
+		},
+		"FieldBoundaries": {
+			reason: "Moving characters between fields changes the fingerprint.",
+			other:  Entry{Rule: "r", File: "pkg/a.yaml.spec", Path: "", Description: "d"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := fingerprint(base) == fingerprint(tc.other); got != tc.equal {
+				t.Errorf("\n%s\nfingerprint(...) == fingerprint(...): want %t, got %t", tc.reason, tc.equal, got)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	root := filepath.FromSlash("/work")
+	a := &xpkg.PackageEntry{Source: filepath.FromSlash("/work/pkg/a.yaml")}
+	b := &xpkg.PackageEntry{Source: filepath.FromSlash("/work/pkg/b.yaml")}
+	issue := func(e *xpkg.PackageEntry, description string) lint.Issue {
+		return lint.Issue{RuleName: "r", Entry: e, Path: jsonpath.NewJSONPath("spec"), Description: description}
+	}
+
+	cases := map[string]struct {
+		reason    string
+		baseline  []lint.Issue
+		issues    []lint.Issue
+		wantNew   []string
+		wantStale []string
+	}{
+		"AllKnown": {
+			reason:   "Known issues are removed.",
+			baseline: []lint.Issue{issue(a, "d1"), issue(b, "d1")},
+			issues:   []lint.Issue{issue(b, "d1"), issue(a, "d1")},
+		},
+		"NewIssue": {
+			reason:   "Issues that are not in the baseline are kept.",
+			baseline: []lint.Issue{issue(a, "d1")},
+			issues:   []lint.Issue{issue(a, "d1"), issue(a, "d2"), issue(b, "d1")},
+			wantNew:  []string{"pkg/a.yaml d2", "pkg/b.yaml d1"},
+		},
+		"AdditionalOccurrence": {
+			reason:   "An entry matches a single issue, so further occurrences are new.",
+			baseline: []lint.Issue{issue(a, "d1")},
+			issues:   []lint.Issue{issue(a, "d1"), issue(a, "d1")},
+			wantNew:  []string{"pkg/a.yaml d1"},
+		},
+		"Stale": {
+			reason:    "Entries without matching issue are stale.",
+			baseline:  []lint.Issue{issue(a, "d1"), issue(a, "d1"), issue(b, "d2")},
+			issues:    []lint.Issue{issue(a, "d1")},
+			wantStale: []string{"pkg/a.yaml d1", "pkg/b.yaml d2"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			bl := New(lint.LinterReport{Issues: tc.baseline}, root)
+			filtered, stale := bl.Filter(lint.LinterReport{Issues: tc.issues}, root)
+
+			gotNew := []string{}
+			for _, i := range filtered.Issues {
+				gotNew = append(gotNew, relativeFile(i.Entry.Source, root)+" "+i.Description)
+			}
+			gotStale := []string{}
+			for _, e := range stale {
+				gotStale = append(gotStale, e.File+" "+e.Description)
+			}
+			if !equalStrings(tc.wantNew, gotNew) {
+				t.Errorf("\n%s\nFilter(...): want new issues %v, got %v", tc.reason, tc.wantNew, gotNew)
+			}
+			if !equalStrings(tc.wantStale, gotStale) {
+				t.Errorf("\n%s\nFilter(...): want stale entries %v, got %v", tc.reason, tc.wantStale, gotStale)
+			}
+		})
+	}
+}
+
+func equalStrings(want, got []string) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package lint
+
+import "strings"
+
+// Diff returns the issues of r that are not part of previous and the issues of
+// previous that are not part of r. Issues are compared by rule, source, path
+// and description since entries are replaced when a package is parsed again.
+func (r LinterReport) Diff(previous LinterReport) (added, fixed []Issue) {
+	current := issueSet(r.Issues)
+	before := issueSet(previous.Issues)
+	for _, iss := range r.Issues {
+		if _, exists := before[iss.key()]; !exists {
+			added = append(added, iss)
+		}
+	}
+	for _, iss := range previous.Issues {
+		if _, exists := current[iss.key()]; !exists {
+			fixed = append(fixed, iss)
+		}
+	}
+	return added, fixed
+}
+
+func issueSet(issues []Issue) map[string]struct{} {
+	set := make(map[string]struct{}, len(issues))
+	for _, iss := range issues {
+		set[iss.key()] = struct{}{}
+	}
+	return set
+}
+
+func (i Issue) key() string {
+	source := ""
+	if i.Entry != nil {
+		source = i.Entry.Source
+	}
+	return strings.Join([]string{i.RuleName, source, i.Path.String(), i.PathValue, i.Description}, "\x00")
+}
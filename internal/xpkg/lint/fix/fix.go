@@ -0,0 +1,243 @@
+// Package fix applies the fixes of lint issues to manifests. Fixes are located
+// with the yaml.v3 node tree and applied as minimal text edits, so comments
+// and formatting of the untouched parts of a manifest are preserved.
+package fix
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errParseManifest      = "failed to parse manifest"
+	errFmtFindPath        = "failed to find %s"
+	errFmtNotScalar       = "%s is not a single line scalar"
+	errFmtNotBlockMapping = "%s is not a block mapping"
+	errFmtFieldExists     = "field '%s' already exists in %s"
+	errFmtUnknownFixType  = "unknown fix type '%s'"
+	errFmtLocateValue     = "failed to locate value of %s"
+	errOverlappingFixes   = "fixes overlap"
+	errFmtConflictingAdds = "fixes add field '%s' to %s more than once"
+	errFixedInvalid       = "fixed manifest is invalid"
+	errMarshalValue       = "failed to marshal value"
+)
+
+// edit replaces the bytes from start to end with text.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// Apply fixes to raw and returns the fixed manifest. Either all fixes are
+// applied or none.
+func Apply(raw []byte, fixes []lint.Fix) ([]byte, error) {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(raw, root); err != nil {
+		return nil, errors.Wrap(err, errParseManifest)
+	}
+	lines := lineOffsets(raw)
+
+	edits := make([]edit, 0, len(fixes))
+	added := map[string]bool{}
+	for _, f := range fixes {
+		if f.Type == lint.FixTypeAddField {
+			// Insertions at the same offset do not overlap, so fields that are
+			// added twice are detected here.
+			field := jsonpath.NewJSONPath(f.Path, f.Key).String()
+			if added[field] {
+				return nil, errors.Errorf(errFmtConflictingAdds, f.Key, f.Path.String())
+			}
+			added[field] = true
+		}
+		e, err := fixEdit(raw, lines, root, f)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, e)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+	fixed := append([]byte{}, raw...)
+	for i, e := range edits {
+		if i > 0 && e.end > edits[i-1].start {
+			return nil, errors.New(errOverlappingFixes)
+		}
+		fixed = append(fixed[:e.start], append([]byte(e.text), fixed[e.end:]...)...)
+	}
+
+	if err := yaml.Unmarshal(fixed, &yaml.Node{}); err != nil {
+		return nil, errors.Wrap(err, errFixedInvalid)
+	}
+	return fixed, nil
+}
+
+func fixEdit(raw []byte, lines []int, root *yaml.Node, f lint.Fix) (edit, error) {
+	node, err := jsonpath.Find(root, f.Path)
+	if err != nil || node == nil {
+		return edit{}, errors.Errorf(errFmtFindPath, f.Path.String())
+	}
+	switch f.Type {
+	case lint.FixTypeSetValue:
+		return setValueEdit(raw, lines, node, f)
+	case lint.FixTypeAddField:
+		return addFieldEdit(raw, lines, node, f)
+	}
+	return edit{}, errors.Errorf(errFmtUnknownFixType, f.Type)
+}
+
+// setValueEdit replaces the scalar node with f.Value in the quoting style of
+// node.
+func setValueEdit(raw []byte, lines []int, node *yaml.Node, f lint.Fix) (edit, error) {
+	if node.Kind != yaml.ScalarNode || strings.Contains(node.Value, "\n") {
+		return edit{}, errors.Errorf(errFmtNotScalar, f.Path.String())
+	}
+	start := offset(raw, lines, node.Line, node.Column)
+	end, ok := scalarEnd(raw, start, node)
+	if start < 0 || !ok {
+		return edit{}, errors.Errorf(errFmtLocateValue, f.Path.String())
+	}
+	text, err := formatScalar(f.Value, node.Style)
+	if err != nil {
+		return edit{}, err
+	}
+	return edit{start: start, end: end, text: text}, nil
+}
+
+// addFieldEdit adds the field f.Key to the block mapping node. The field is
+// inserted after the last line of the mapping with the indentation of its
+// keys.
+func addFieldEdit(raw []byte, lines []int, node *yaml.Node, f lint.Fix) (edit, error) {
+	if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 || len(node.Content) == 0 {
+		return edit{}, errors.Errorf(errFmtNotBlockMapping, f.Path.String())
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == f.Key {
+			return edit{}, errors.Errorf(errFmtFieldExists, f.Key, f.Path.String())
+		}
+	}
+	value, err := formatScalar(f.Value, 0)
+	if err != nil {
+		return edit{}, err
+	}
+
+	keyStart := offset(raw, lines, node.Content[0].Line, node.Content[0].Column)
+	if keyStart < 0 {
+		return edit{}, errors.Errorf(errFmtLocateValue, f.Path.String())
+	}
+	indent := keyStart - lines[node.Content[0].Line-1]
+	text := strings.Repeat(" ", indent) + f.Key + ": " + value + "\n"
+
+	last := lastLine(node)
+	if last >= len(lines) {
+		// The mapping ends in the last line of a file without trailing
+		// newline.
+		return edit{start: len(raw), end: len(raw), text: "\n" + strings.TrimSuffix(text, "\n")}, nil
+	}
+	return edit{start: lines[last], end: lines[last], text: text}, nil
+}
+
+// lineOffsets returns the offset of the start of each line of raw.
+func lineOffsets(raw []byte) []int {
+	offsets := []int{0}
+	for i, b := range raw {
+		if b == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// offset returns the byte offset of line and column (both 1-based, column in
+// runes) or -1 if it is out of range.
+func offset(raw []byte, lines []int, line, column int) int {
+	if line < 1 || line > len(lines) {
+		return -1
+	}
+	o := lines[line-1]
+	for i := 1; i < column; i++ {
+		if o >= len(raw) || raw[o] == '\n' {
+			return -1
+		}
+		_, size := utf8.DecodeRune(raw[o:])
+		o += size
+	}
+	return o
+}
+
+// scalarEnd returns the offset behind the scalar node that starts at start.
+func scalarEnd(raw []byte, start int, node *yaml.Node) (int, bool) {
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		return quotedEnd(raw, start, '"', true)
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		return quotedEnd(raw, start, '\'', false)
+	case node.Style == 0 || node.Style == yaml.TaggedStyle:
+		end := start + len(node.Value)
+		if end > len(raw) || string(raw[start:end]) != node.Value {
+			return 0, false
+		}
+		return end, true
+	}
+	return 0, false
+}
+
+// quotedEnd returns the offset behind the quoted scalar that starts at start.
+// Double quoted scalars escape with backslashes, single quoted scalars by
+// doubling the quote.
+func quotedEnd(raw []byte, start int, quote byte, backslash bool) (int, bool) {
+	if start >= len(raw) || raw[start] != quote {
+		return 0, false
+	}
+	for i := start + 1; i < len(raw) && raw[i] != '\n'; i++ {
+		switch {
+		case backslash && raw[i] == '\\':
+			i++
+		case raw[i] == quote && !backslash && i+1 < len(raw) && raw[i+1] == quote:
+			i++
+		case raw[i] == quote:
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// formatScalar formats value as YAML scalar in style. Falls back to the style
+// chosen by yaml.v3 for plain scalars.
+func formatScalar(value string, style yaml.Style) (string, error) {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value), nil
+	case style&yaml.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'", nil
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return "", errors.Wrap(err, errMarshalValue)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// lastLine returns the last line (1-based) of node and its children.
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	if node.Kind == yaml.ScalarNode && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		last += strings.Count(strings.TrimSuffix(node.Value, "\n"), "\n") + 1
+	}
+	for _, c := range node.Content {
+		if l := lastLine(c); l > last {
+			last = l
+		}
+	}
+	return last
+}
@@ -0,0 +1,100 @@
+package fix
+
+import (
+	"testing"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const manifest = `apiVersion: example.org/v1
+kind: Example
+spec:
+  # The name.
+  name: "old"
+  size: small
+`
+
+func TestApply(t *testing.T) {
+	namePath := jsonpath.NewJSONPath("spec", "name")
+	sizePath := jsonpath.NewJSONPath("spec", "size")
+	specPath := jsonpath.NewJSONPath("spec")
+
+	cases := map[string]struct {
+		reason  string
+		fixes   []lint.Fix
+		want    string
+		wantErr bool
+	}{
+		"SetValue": {
+			reason: "Values are replaced in their quoting style and comments are kept.",
+			fixes:  []lint.Fix{{Type: lint.FixTypeSetValue, Path: namePath, Value: "new"}},
+			want: `apiVersion: example.org/v1
+kind: Example
+spec:
+  # The name.
+  name: "new"
+  size: small
+`,
+		},
+		"AddField": {
+			reason: "Fields are added at the end of the mapping with the indentation of its keys.",
+			fixes:  []lint.Fix{{Type: lint.FixTypeAddField, Path: specPath, Key: "color", Value: "red"}},
+			want:   manifest + "  color: red\n",
+		},
+		"DistinctFixes": {
+			reason: "Fixes of different values are applied together.",
+			fixes: []lint.Fix{
+				{Type: lint.FixTypeSetValue, Path: namePath, Value: "new"},
+				{Type: lint.FixTypeSetValue, Path: sizePath, Value: "large"},
+			},
+			want: `apiVersion: example.org/v1
+kind: Example
+spec:
+  # The name.
+  name: "new"
+  size: large
+`,
+		},
+		"OverlappingFixes": {
+			reason:  "Fixes of the same value overlap and are not applied.",
+			fixes:   []lint.Fix{{Type: lint.FixTypeSetValue, Path: namePath, Value: "a"}, {Type: lint.FixTypeSetValue, Path: namePath, Value: "b"}},
+			wantErr: true,
+		},
+		"ConflictingAddFields": {
+			reason: "Fixes adding the same field conflict and are not applied.",
+			fixes: []lint.Fix{
+				{Type: lint.FixTypeAddField, Path: specPath, Key: "color", Value: "red"},
+				{Type: lint.FixTypeAddField, Path: specPath, Key: "color", Value: "blue"},
+			},
+			wantErr: true,
+		},
+		"FieldExists": {
+			reason:  "A field that already exists is not added.",
+			fixes:   []lint.Fix{{Type: lint.FixTypeAddField, Path: specPath, Key: "name", Value: "new"}},
+			wantErr: true,
+		},
+		"PathNotFound": {
+			reason:  "Fixes of missing paths fail.",
+			fixes:   []lint.Fix{{Type: lint.FixTypeSetValue, Path: jsonpath.NewJSONPath("spec", "missing"), Value: "new"}},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Apply([]byte(manifest), tc.fixes)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("\n%s\nApply(...): want error, got:\n%s", tc.reason, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("\n%s\nApply(...): unexpected error: %v", tc.reason, err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("\n%s\nApply(...): want:\n%s\ngot:\n%s", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package lint
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -10,23 +15,229 @@ import (
 
 // Linter checks if a package for issues.
 type Linter interface {
-	// Lint pkg.
-	Lint(pkg *xpkg.Package) LinterReport
+	// Lint pkg. Returns the issues discovered so far and the error of ctx if
+	// ctx is done before all rules finished. Returns the issues of all rules
+	// and a *RunError if rules failed to run.
+	Lint(ctx context.Context, pkg *xpkg.Package) (LinterReport, error)
+}
+
+// RuleError is the failure of a rule to run, for example of a plugin that
+// crashed.
+type RuleError struct {
+	RuleName string
+	Err      error
+}
+
+func (e RuleError) Error() string {
+	return fmt.Sprintf(errFmtRuleFailed, e.RuleName, e.Err)
+}
+
+func (e RuleError) Unwrap() error {
+	return e.Err
+}
+
+// RunError is returned by Linter.Lint if rules failed to run. The issues of
+// the report are incomplete in this case.
+type RunError struct {
+	// Errors of the failed rules sorted by rule name.
+	Errors []RuleError
+}
+
+func (e *RunError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
 }
 
 type LinterReport struct {
 	Issues []Issue
 }
 
+// CountBySeverity returns the number of issues of r per severity.
+func (r LinterReport) CountBySeverity() map[Severity]int {
+	counts := map[Severity]int{}
+	for _, issue := range r.Issues {
+		counts[issue.Severity]++
+	}
+	return counts
+}
+
+// Severity of an Issue.
+type Severity string
+
+const (
+	// SeverityError is used for issues that break a package.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is used for issues that are likely unintended but do
+	// not break a package.
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo is used for informational issues.
+	SeverityInfo Severity = "info"
+)
+
+const (
+	errFmtUnknownSeverity = "unknown severity '%s', expected '%s', '%s' or '%s'"
+	errFmtRuleFailed      = "rule '%s' failed to run: %v"
+)
+
+// Severities returns all severities from the highest to the lowest.
+func Severities() []Severity {
+	return []Severity{SeverityError, SeverityWarning, SeverityInfo}
+}
+
+// ParseSeverity parses s as Severity.
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range Severities() {
+		if string(sev) == s {
+			return sev, nil
+		}
+	}
+	return "", errors.Errorf(errFmtUnknownSeverity, s, SeverityError, SeverityWarning, SeverityInfo)
+}
+
+// AtLeast determines if s is as high as or higher than threshold.
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s.rank() <= threshold.rank()
+}
+
+func (s Severity) rank() int {
+	for i, sev := range Severities() {
+		if sev == s {
+			return i
+		}
+	}
+	// Issues without severity are treated as errors.
+	return 0
+}
+
 type Issue struct {
 	RuleName    string
+	Severity    Severity
 	Entry       *xpkg.PackageEntry
 	Path        jsonpath.JSONPath
 	PathValue   string
 	Description string
+
+	// Related are other locations that are affected by the issue, for
+	// example the resources that use a broken patch set.
+	Related []Location
+
+	// Suggestions are values that may replace PathValue to resolve the
+	// issue, most likely first.
+	Suggestions []string
+
+	// Fixes that resolve the issue when applied together. Only set if the
+	// fix is unambiguous.
+	Fixes []Fix
+
+	// Schema the issue was validated against, see SchemaOrigin.String. Empty
+	// if the issue is not about a schema.
+	Schema string
+}
+
+// Location is a path within a package entry.
+type Location struct {
+	Entry *xpkg.PackageEntry
+	Path  jsonpath.JSONPath
+}
+
+// SchemaOrigin describes where the schema of a kind is defined.
+type SchemaOrigin struct {
+	// Package that defines the schema, for example a package image or a
+	// directory.
+	Package string
+
+	// Entry that defines the schema. Nil if the schema is not defined by a
+	// manifest, for example for the bundled Kubernetes schemas.
+	Entry *xpkg.PackageEntry
+
+	// Kind of the definition, for example CRD or XRD.
+	Kind string
+
+	// Name of the definition.
+	Name string
+}
+
+// String returns a description of o, for example
+// "crossplanecontrib/provider-aws:v0.34.0 CRD rdsinstances.database.aws.crossplane.io".
+func (o SchemaOrigin) String() string {
+	if o.Package == "" {
+		return fmt.Sprintf("%s %s", o.Kind, o.Name)
+	}
+	return fmt.Sprintf("%s %s %s", o.Package, o.Kind, o.Name)
+}
+
+// SchemaConflict is a kind that is defined more than once with different
+// schemas.
+type SchemaConflict struct {
+	GVK schema.GroupVersionKind
+
+	// Used is the definition the kind is validated against.
+	Used SchemaOrigin
+
+	// Ignored is the conflicting definition.
+	Ignored SchemaOrigin
+}
+
+// FixType is the type of operation of a Fix.
+type FixType string
+
+const (
+	// FixTypeSetValue replaces the scalar at Path with Value.
+	FixTypeSetValue FixType = "SetValue"
+
+	// FixTypeAddField adds the field Key with the scalar Value to the mapping
+	// at Path.
+	FixTypeAddField FixType = "AddField"
+)
+
+// Fix is a change of the manifest of an issue's entry.
+type Fix struct {
+	Type        FixType
+	Description string
+	Path        jsonpath.JSONPath
+	Key         string
+	Value       string
 }
 
 type LinterContext interface {
+	// Context of the linter run. Rules should stop when it is done.
+	Context() context.Context
+
+	// ReportIssue reports issue. The name and default severity of the rule
+	// are used if issue.RuleName or issue.Severity are empty.
 	ReportIssue(issue Issue)
+
+	// ReportError reports that the rule failed to run, for example because
+	// an external command crashed. The linter run fails with a RunError.
+	ReportError(err error)
 	GetCRDSchema(gvk schema.GroupVersionKind) *extv1.CustomResourceDefinitionVersion
+	HasFunction(name string) bool
+	GetEnvironmentSchema() *extv1.JSONSchemaProps
+}
+
+// SchemaOrigins looks up where the schema of a kind is defined.
+type SchemaOrigins interface {
+	// GetSchemaOrigin returns where the schema of gvk is defined or nil if it
+	// is unknown.
+	GetSchemaOrigin(gvk schema.GroupVersionKind) *SchemaOrigin
+}
+
+// SchemaInspector is optionally implemented by a LinterContext that knows
+// where schemas are defined. Rules must check for it with a type assertion,
+// so LinterContext does not grow with every lookup.
+type SchemaInspector interface {
+	SchemaOrigins
+
+	// GetSchemaConflicts returns the kinds that are defined more than once
+	// with different schemas.
+	GetSchemaConflicts() []SchemaConflict
+
+	// GetKindDefinitions returns all manifests that define gk, including
+	// those whose schemas are not used.
+	GetKindDefinitions(gk schema.GroupKind) []SchemaOrigin
 }
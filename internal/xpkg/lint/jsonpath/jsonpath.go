@@ -3,6 +3,10 @@ package jsonpath
 import (
 	"fmt"
 	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
+	"gopkg.in/yaml.v3"
 )
 
 type JSONPathSegment interface {
@@ -50,3 +54,35 @@ func NewJSONPath(rawSegments ...any) JSONPath {
 	}
 	return path
 }
+
+// Parse a path in the field path notation of Crossplane patches, for example
+// spec.resources[0].base.
+func Parse(rawPath string) (JSONPath, error) {
+	segments, err := fieldpath.Parse(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	path := make(JSONPath, len(segments))
+	for i, s := range segments {
+		if s.Type == fieldpath.SegmentIndex {
+			path[i] = IndexSegment(s.Index)
+		} else {
+			path[i] = FieldSegment(s.Field)
+		}
+	}
+	return path, nil
+}
+
+// Find returns the first node of root that matches path or nil if no node
+// matches.
+func Find(root *yaml.Node, path JSONPath) (*yaml.Node, error) {
+	yamlPath, err := yamlpath.NewPath(path.String())
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := yamlPath.Find(root)
+	if err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+	return nodes[0], nil
+}
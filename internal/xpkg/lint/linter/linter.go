@@ -1,35 +1,125 @@
 package lint
 
 import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"github.com/crossplane-contrib/crossplane-lint/internal/config"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter/rules"
 	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
 )
 
+const (
+	customRulePrefix = "custom."
+	pluginRulePrefix = "plugin."
+
+	errFmtDuplicateCustomRule = "custom rule '%s' is defined more than once"
+	errFmtBuildCustomRule     = "failed to build custom rule '%s'"
+	errFmtDuplicatePlugin     = "plugin '%s' is defined more than once"
+	errFmtBuildPlugin         = "failed to build plugin '%s'"
+	errFmtRuleSeverity        = "invalid severity of rule '%s'"
+)
+
 type linterContext struct {
+	ctx         context.Context
 	ruleName    string
 	issueChan   chan lint.Issue
+	errChan     chan lint.RuleError
 	schemaStore *lintschema.SchemaStore
+
+	// severity overrides the severity of all issues of the rule if set.
+	severity lint.Severity
+}
+
+func (c *linterContext) Context() context.Context {
+	return c.ctx
 }
 
 func (c *linterContext) ReportIssue(issue lint.Issue) {
-	issue.RuleName = c.ruleName
-	c.issueChan <- issue
+	if issue.RuleName == "" {
+		issue.RuleName = c.ruleName
+	}
+	switch {
+	case c.severity != "":
+		issue.Severity = c.severity
+	case issue.Severity == "":
+		issue.Severity = defaultSeverity(c.ruleName)
+	}
+	select {
+	case c.issueChan <- issue:
+	case <-c.ctx.Done():
+	}
+}
+
+func (c *linterContext) ReportError(err error) {
+	select {
+	case c.errChan <- lint.RuleError{RuleName: c.ruleName, Err: err}:
+	case <-c.ctx.Done():
+	}
 }
 
 func (c *linterContext) GetCRDSchema(gvk schema.GroupVersionKind) *extv1.CustomResourceDefinitionVersion {
 	return c.schemaStore.GetCRDSchema(gvk)
 }
 
+func (c *linterContext) GetSchemaOrigin(gvk schema.GroupVersionKind) *lint.SchemaOrigin {
+	return c.schemaStore.GetSchemaOrigin(gvk)
+}
+
+func (c *linterContext) GetSchemaConflicts() []lint.SchemaConflict {
+	return c.schemaStore.GetSchemaConflicts()
+}
+
+func (c *linterContext) GetKindDefinitions(gk schema.GroupKind) []lint.SchemaOrigin {
+	return c.schemaStore.GetKindDefinitions(gk)
+}
+
+func (c *linterContext) HasFunction(name string) bool {
+	return c.schemaStore.HasFunction(name)
+}
+
+func (c *linterContext) GetEnvironmentSchema() *extv1.JSONSchemaProps {
+	return c.schemaStore.GetEnvironmentSchema()
+}
+
 var defaultRules = map[string]LinterRule{
-	"generic.checkDuplicates":         LinterRuleFunc(rules.CheckDuplicateObjects),
-	"composition.checkCompositeType":  LinterRuleFunc(rules.CheckCompositionCompositeTypeRef),
-	"composition.checkPathFieldPaths": LinterRuleFunc(rules.CheckCompositionFieldPaths),
+	"generic.checkDuplicates":            LinterRuleFunc(rules.CheckDuplicateObjects),
+	"generic.checkSchemaConflicts":       LinterRuleFunc(rules.CheckSchemaConflicts),
+	"composition.checkCompositeType":     LinterRuleFunc(rules.CheckCompositionCompositeTypeRef),
+	"composition.checkPathFieldPaths":    LinterRuleFunc(rules.CheckCompositionFieldPaths),
+	"composition.checkEnvironment":       LinterRuleFunc(rules.CheckCompositionEnvironment),
+	"composition.checkFunctions":         LinterRuleFunc(rules.CheckCompositionFunctions),
+	"composition.checkResourceNames":     LinterRuleFunc(rules.CheckCompositionResourceNames),
+	"composition.checkPatchPolicies":     LinterRuleFunc(rules.CheckCompositionPatchPolicies),
+	"composition.checkEmbeddedManifests": LinterRuleFunc(rules.CheckCompositionEmbeddedManifests),
+	"composition.checkNestedComposites":  LinterRuleFunc(rules.CheckCompositionNestedComposites),
+	"composition.checkSelection":         LinterRuleFunc(rules.CheckCompositionSelection),
+	"xrd.checkFieldUsage":                LinterRuleFunc(rules.CheckXRDFieldUsage),
+	"xrd.checkNames":                     LinterRuleFunc(rules.CheckXRDNames),
+}
+
+// defaultSeverities are the severities of the issues of rules that do not
+// set a severity. Rules not listed default to lint.SeverityError.
+var defaultSeverities = map[string]lint.Severity{
+	"xrd.checkFieldUsage":          lint.SeverityWarning,
+	"generic.checkSchemaConflicts": lint.SeverityWarning,
+	"composition.checkSelection":   lint.SeverityWarning,
+	"composition.checkComplexity":  lint.SeverityWarning,
+}
+
+func defaultSeverity(ruleName string) lint.Severity {
+	if sev, exists := defaultSeverities[ruleName]; exists {
+		return sev
+	}
+	return lint.SeverityError
 }
 
 var _ lint.Linter = &linter{}
@@ -47,43 +137,199 @@ func (f LinterRuleFunc) Validate(ctx lint.LinterContext, pkg *xpkg.Package) {
 type linter struct {
 	schemaValidator *lintschema.SchemaStore
 	rules           map[string]LinterRule
+	severities      map[string]lint.Severity
+}
+
+// LinterOption configures a linter.
+type LinterOption func(l *linter)
+
+// WithRule adds rule to the linter. Replaces the rule of the same name.
+func WithRule(name string, rule LinterRule) LinterOption {
+	return func(l *linter) {
+		l.rules[name] = rule
+	}
+}
+
+// WithoutRule removes the rule name from the linter.
+func WithoutRule(name string) LinterOption {
+	return func(l *linter) {
+		delete(l.rules, name)
+	}
 }
 
-func Newlinter(schemaValidator *lintschema.SchemaStore) lint.Linter {
-	return &linter{
+// WithSeverity overrides the severity of all issues of the rule name.
+func WithSeverity(name string, severity lint.Severity) LinterOption {
+	return func(l *linter) {
+		l.severities[name] = severity
+	}
+}
+
+// WithSeverities overrides the severities of rules by rule name.
+func WithSeverities(severities map[string]string) ([]LinterOption, error) {
+	opts := make([]LinterOption, 0, len(severities))
+	for name, s := range severities {
+		sev, err := lint.ParseSeverity(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtRuleSeverity, name)
+		}
+		opts = append(opts, WithSeverity(name, sev))
+	}
+	return opts, nil
+}
+
+// DefaultRuleNames returns the names of the default rules.
+func DefaultRuleNames() []string {
+	names := make([]string, 0, len(defaultRules))
+	for name := range defaultRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Newlinter(schemaValidator *lintschema.SchemaStore, opts ...LinterOption) lint.Linter {
+	l := &linter{
 		schemaValidator: schemaValidator,
-		rules:           defaultRules,
+		rules:           make(map[string]LinterRule, len(defaultRules)),
+		severities:      map[string]lint.Severity{},
+	}
+	for name, rule := range defaultRules {
+		l.rules[name] = rule
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// WithCustomRules adds the rules defined by customRules. Their names are
+// prefixed with custom.
+func WithCustomRules(customRules []config.CustomRule) ([]LinterOption, error) {
+	opts := make([]LinterOption, 0, len(customRules))
+	names := map[string]struct{}{}
+	for _, r := range customRules {
+		if _, exists := names[r.Name]; exists {
+			return nil, errors.Errorf(errFmtDuplicateCustomRule, r.Name)
+		}
+		names[r.Name] = struct{}{}
+		rule, err := rules.NewCustomRule(r)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtBuildCustomRule, r.Name)
+		}
+		opts = append(opts, WithRule(customRulePrefix+r.Name, LinterRuleFunc(rule.Check)))
 	}
+	return opts, nil
 }
 
-func (l *linter) Lint(pkg *xpkg.Package) lint.LinterReport {
-	issueChan := l.runRulesConcurrently(pkg)
+// WithComplexityThresholds adds composition.checkComplexity with thresholds.
+// The rule is not added if no threshold is set.
+func WithComplexityThresholds(thresholds config.ComplexityThresholds) []LinterOption {
+	if !thresholds.IsSet() {
+		return nil
+	}
+	rule := rules.NewComplexityRule(thresholds)
+	return []LinterOption{WithRule("composition.checkComplexity", LinterRuleFunc(rule.Check))}
+}
+
+// WithPlugins adds a rule for each of plugins. Their names are prefixed with
+// plugin.
+func WithPlugins(plugins []config.Plugin) ([]LinterOption, error) {
+	opts := make([]LinterOption, 0, len(plugins))
+	names := map[string]struct{}{}
+	for _, p := range plugins {
+		rule, err := rules.NewPluginRule(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtBuildPlugin, p.Command)
+		}
+		if _, exists := names[rule.Name()]; exists {
+			return nil, errors.Errorf(errFmtDuplicatePlugin, rule.Name())
+		}
+		names[rule.Name()] = struct{}{}
+		opts = append(opts, WithRule(pluginRulePrefix+rule.Name(), LinterRuleFunc(rule.Check)))
+	}
+	return opts, nil
+}
+
+// WithoutPlugins removes the rules of all plugins added by WithPlugins from the
+// linter. Must follow the options of WithPlugins.
+func WithoutPlugins() LinterOption {
+	return func(l *linter) {
+		for name := range l.rules {
+			if IsPluginRule(name) {
+				delete(l.rules, name)
+			}
+		}
+	}
+}
+
+// IsPluginRule determines if the issues of the rule name are reported by a
+// plugin.
+func IsPluginRule(name string) bool {
+	return strings.HasPrefix(name, pluginRulePrefix)
+}
+
+func (l *linter) Lint(ctx context.Context, pkg *xpkg.Package) (lint.LinterReport, error) {
+	issueChan, errChan := l.runRulesConcurrently(ctx, pkg)
 	report := lint.LinterReport{}
-	for iss := range issueChan {
-		report.Issues = append(report.Issues, iss)
+	runErr := &lint.RunError{}
+	for issueChan != nil || errChan != nil {
+		select {
+		case iss, ok := <-issueChan:
+			if !ok {
+				issueChan = nil
+				continue
+			}
+			report.Issues = append(report.Issues, iss)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			runErr.Errors = append(runErr.Errors, err)
+		case <-ctx.Done():
+			report.Issues = lint.Merge(report.Issues)
+			return report, ctx.Err()
+		}
+	}
+	report.Issues = lint.Merge(report.Issues)
+	if len(runErr.Errors) > 0 {
+		sort.SliceStable(runErr.Errors, func(i, j int) bool {
+			return runErr.Errors[i].RuleName < runErr.Errors[j].RuleName
+		})
+		return report, runErr
 	}
-	return report
+	return report, nil
 }
 
-func (l *linter) runRulesConcurrently(pkg *xpkg.Package) chan lint.Issue {
+// runRulesConcurrently runs all rules and returns the channels of their
+// issues and errors. Both are closed when all rules finished.
+func (l *linter) runRulesConcurrently(ctx context.Context, pkg *xpkg.Package) (chan lint.Issue, chan lint.RuleError) {
 	issueChan := make(chan lint.Issue)
+	errChan := make(chan lint.RuleError)
 	eg := errgroup.Group{}
 
 	for name, r := range l.rules {
-		ctx := &linterContext{
+		rctx := &linterContext{
+			ctx:         ctx,
 			ruleName:    name,
 			issueChan:   issueChan,
+			errChan:     errChan,
 			schemaStore: l.schemaValidator,
+			severity:    l.severities[name],
 		}
 		rule := r
 		eg.Go(func() error {
-			rule.Validate(ctx, pkg)
+			if ctx.Err() != nil {
+				return nil
+			}
+			rule.Validate(rctx, pkg)
 			return nil
 		})
 	}
 	go func() {
 		_ = eg.Wait()
 		close(issueChan)
+		close(errChan)
 	}()
-	return issueChan
+	return issueChan, errChan
 }
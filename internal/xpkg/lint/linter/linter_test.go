@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+)
+
+func TestLint(t *testing.T) {
+	report := LinterRuleFunc(func(ctx lint.LinterContext, _ *xpkg.Package) {
+		ctx.ReportIssue(lint.Issue{Description: "found"})
+	})
+	fail := func(msg string) LinterRule {
+		return LinterRuleFunc(func(ctx lint.LinterContext, _ *xpkg.Package) {
+			ctx.ReportError(errors.New(msg))
+		})
+	}
+	cases := map[string]struct {
+		reason     string
+		rules      map[string]LinterRule
+		wantIssues []string
+		wantErr    string
+	}{
+		"Issues": {
+			reason:     "Issues are reported with the name of their rule.",
+			rules:      map[string]LinterRule{"test.report": report},
+			wantIssues: []string{"test.report: found"},
+		},
+		"RuleErrors": {
+			reason:     "Errors of rules fail the run, sorted by rule name. The issues of the other rules are returned.",
+			rules:      map[string]LinterRule{"test.report": report, "test.b": fail("crashed"), "test.a": fail("timed out")},
+			wantIssues: []string{"test.report: found"},
+			wantErr:    "rule 'test.a' failed to run: timed out; rule 'test.b' failed to run: crashed",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []LinterOption{}
+			for _, n := range DefaultRuleNames() {
+				opts = append(opts, WithoutRule(n))
+			}
+			for n, r := range tc.rules {
+				opts = append(opts, WithRule(n, r))
+			}
+			got, err := Newlinter(lintschema.NewSchemaStore(), opts...).Lint(context.Background(), &xpkg.Package{})
+
+			gotIssues := []string{}
+			for _, i := range got.Issues {
+				gotIssues = append(gotIssues, i.RuleName+": "+i.Description)
+			}
+			if !reflect.DeepEqual(tc.wantIssues, gotIssues) {
+				t.Errorf("\n%s\nLint(...): want issues %q, got %q", tc.reason, tc.wantIssues, gotIssues)
+			}
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+				runErr := &lint.RunError{}
+				if !errors.As(err, &runErr) {
+					t.Errorf("\n%s\nLint(...): want *lint.RunError, got %T", tc.reason, err)
+				}
+			}
+			if tc.wantErr != gotErr {
+				t.Errorf("\n%s\nLint(...): want error %q, got %q", tc.reason, tc.wantErr, gotErr)
+			}
+		})
+	}
+}
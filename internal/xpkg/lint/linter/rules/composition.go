@@ -1,12 +1,18 @@
 package rules
 
 import (
+	"fmt"
+	"strings"
+
 	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
 )
 
 const (
@@ -14,6 +20,12 @@ const (
 
 	errParseGroupVersion       = "failed to convert object to Composition"
 	errNoMatchingCompositeType = "no composite type found for %s.%s/%s"
+
+	errMixedResourceNames     = "either all or none of the resources must have a name"
+	errFmtDuplicateResource   = "resource name is already used by resource %d"
+	errFmtInvalidResourceName = "invalid resource name: %s"
+	errEmptyResourceName      = "must not be empty"
+	errFmtFixAddResourceName  = "add name '%s'"
 )
 
 // CheckCompositionCompositeTypeRef checks if a composition in manifest points
@@ -74,3 +86,116 @@ func getCompositeXRD(pkg *xpkg.Package, compositeGVK schema.GroupVersionKind) (*
 	}
 	return nil, nil
 }
+
+// CheckCompositionResourceNames checks if the resources of a composition are
+// either all named or all anonymous and if the names are valid and unique.
+func CheckCompositionResourceNames(ctx lint.LinterContext, pkg *xpkg.Package) {
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		for _, t := range getComposedTemplates(e, comp) {
+			checkResourceNames(ctx, e, t)
+		}
+	}
+}
+
+// generateResourceNames returns a unique name for each unnamed resource of
+// comp. Names are derived from the kind of the base.
+func generateResourceNames(comp *xpv1.Composition) map[int]string {
+	taken := map[string]bool{}
+	for _, r := range comp.Spec.Resources {
+		if r.Name != nil {
+			taken[*r.Name] = true
+		}
+	}
+	names := map[int]string{}
+	for i, r := range comp.Spec.Resources {
+		if r.Name != nil {
+			continue
+		}
+		prefix := "resource"
+		base := &unstructured.Unstructured{}
+		if err := base.UnmarshalJSON(r.Base.Raw); err == nil && base.GetKind() != "" {
+			prefix = strings.ToLower(base.GetKind())
+		}
+		name := prefix
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s-%d", prefix, n)
+		}
+		taken[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+func checkResourceNames(ctx lint.LinterContext, e *xpkg.PackageEntry, t composedTemplates) {
+	comp := t.Composition
+	named := 0
+	for _, r := range comp.Spec.Resources {
+		if r.Name != nil {
+			named++
+		}
+	}
+	if named > 0 && named < len(comp.Spec.Resources) {
+		names := generateResourceNames(comp)
+		for i, r := range comp.Spec.Resources {
+			if r.Name == nil {
+				path := jsonpath.NewJSONPath(t.Path, "resources", i)
+				ctx.ReportIssue(lint.Issue{
+					Entry:       e,
+					Path:        path,
+					Description: errMixedResourceNames,
+					Fixes: []lint.Fix{{
+						Type:        lint.FixTypeAddField,
+						Description: fmt.Sprintf(errFmtFixAddResourceName, names[i]),
+						Path:        path,
+						Key:         "name",
+						Value:       names[i],
+					}},
+				})
+			}
+		}
+	}
+
+	names := map[string]int{}
+	for i, r := range comp.Spec.Resources {
+		if r.Name == nil {
+			continue
+		}
+		path := jsonpath.NewJSONPath(t.Path, "resources", i, "name")
+		if errs := validateResourceName(*r.Name); len(errs) > 0 {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        path,
+				PathValue:   *r.Name,
+				Description: fmt.Sprintf(errFmtInvalidResourceName, strings.Join(errs, "; ")),
+			})
+		}
+		if first, exists := names[*r.Name]; exists {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        path,
+				PathValue:   *r.Name,
+				Description: fmt.Sprintf(errFmtDuplicateResource, first),
+			})
+			continue
+		}
+		names[*r.Name] = i
+	}
+}
+
+// validateResourceName validates name using the rules of label values as
+// names are used as label and annotation values on composed resources.
+func validateResourceName(name string) []string {
+	if name == "" {
+		return []string{errEmptyResourceName}
+	}
+	return validation.IsValidLabelValue(name)
+}
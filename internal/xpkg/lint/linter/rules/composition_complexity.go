@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/config"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/stats"
+)
+
+const (
+	errFmtComplexityExceeded = "%s of %d exceeds the maximum of %d"
+)
+
+// ComplexityRule reports compositions whose complexity metrics exceed the
+// configured thresholds.
+type ComplexityRule struct {
+	thresholds config.ComplexityThresholds
+}
+
+// NewComplexityRule creates a new ComplexityRule that checks thresholds.
+func NewComplexityRule(thresholds config.ComplexityThresholds) *ComplexityRule {
+	return &ComplexityRule{thresholds: thresholds}
+}
+
+// Check the compositions of pkg.
+func (r *ComplexityRule) Check(ctx lint.LinterContext, pkg *xpkg.Package) {
+	origins, _ := ctx.(lint.SchemaOrigins)
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		s := stats.ComputeComposition(pkg, e, comp, origins)
+		metrics := []struct {
+			name       string
+			value, max int
+		}{
+			{"resource count", s.Resources, r.thresholds.MaxResources},
+			{"patch count", s.Patches, r.thresholds.MaxPatches},
+			{"transform depth", s.TransformDepth, r.thresholds.MaxTransformDepth},
+			{"number of composed kinds", len(s.Kinds), r.thresholds.MaxKinds},
+			{"number of providers", len(s.Providers), r.thresholds.MaxProviders},
+			{"manifest size in bytes", s.Size, r.thresholds.MaxSize},
+		}
+		for _, m := range metrics {
+			if m.max > 0 && m.value > m.max {
+				ctx.ReportIssue(lint.Issue{
+					Entry:       e,
+					Description: fmt.Sprintf(errFmtComplexityExceeded, m.name, m.value, m.max),
+				})
+			}
+		}
+	}
+}
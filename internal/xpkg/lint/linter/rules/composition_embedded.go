@@ -0,0 +1,244 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtEmbeddedIntOrString = "expected type 'integer' or 'string' but got '%s'"
+)
+
+// embeddedManifest is a manifest embedded in a composed resource.
+type embeddedManifest struct {
+	// Fields are the path of the manifest within the composed resource.
+	Fields []string
+	GVK    schema.GroupVersionKind
+	Object map[string]interface{}
+}
+
+// Path of the manifest within the composed resource.
+func (m *embeddedManifest) Path() jsonpath.JSONPath {
+	path := make(jsonpath.JSONPath, len(m.Fields))
+	for i, f := range m.Fields {
+		path[i] = jsonpath.FieldSegment(f)
+	}
+	return path
+}
+
+// getEmbeddedManifest returns the manifest embedded in base or nil if base
+// does not embed a manifest with apiVersion and kind.
+func getEmbeddedManifest(base *unstructured.Unstructured) *embeddedManifest {
+	fields, obj := xpkg.GetEmbeddedManifest(base)
+	if obj == nil {
+		return nil
+	}
+	u := unstructured.Unstructured{Object: obj}
+	gvk := u.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return nil
+	}
+	return &embeddedManifest{Fields: fields, GVK: gvk, Object: obj}
+}
+
+// composedResourceSchema returns the schema of the composed resource base.
+// If base embeds a manifest of a known kind, the schema of the embedded
+// manifest is used for its field. Returns baseSchema otherwise.
+func composedResourceSchema(ctx lint.LinterContext, base *unstructured.Unstructured, baseSchema *extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	m := getEmbeddedManifest(base)
+	if m == nil {
+		return baseSchema
+	}
+	crd := ctx.GetCRDSchema(m.GVK)
+	if crd == nil || crd.Schema == nil || crd.Schema.OpenAPIV3Schema == nil {
+		return baseSchema
+	}
+	return withEmbeddedSchema(baseSchema, m.Fields, crd.Schema.OpenAPIV3Schema)
+}
+
+// withEmbeddedSchema returns a copy of root with the property at fields
+// replaced by embedded. Missing intermediate properties are added.
+func withEmbeddedSchema(root *extv1.JSONSchemaProps, fields []string, embedded *extv1.JSONSchemaProps) *extv1.JSONSchemaProps {
+	res := root.DeepCopy()
+	setProperty(res, fields, embedded)
+	return res
+}
+
+func setProperty(props *extv1.JSONSchemaProps, fields []string, value *extv1.JSONSchemaProps) {
+	if props.Properties == nil {
+		props.Properties = map[string]extv1.JSONSchemaProps{}
+	}
+	if len(fields) == 1 {
+		props.Properties[fields[0]] = *value
+		return
+	}
+	child := props.Properties[fields[0]]
+	if child.Type == "" {
+		child.Type = "object"
+	}
+	setProperty(&child, fields[1:], value)
+	props.Properties[fields[0]] = child
+}
+
+// CheckCompositionEmbeddedManifests validates the manifests embedded in
+// composed resources, such as the manifest of a provider-kubernetes Object,
+// against the schema of their kind. Missing schemas are reported by
+// composition.checkPathFieldPaths.
+func CheckCompositionEmbeddedManifests(ctx lint.LinterContext, pkg *xpkg.Package) {
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		for _, t := range getComposedTemplates(e, comp) {
+			for ri, r := range t.Composition.Spec.Resources {
+				base := &unstructured.Unstructured{}
+				if err := yaml.Unmarshal(r.Base.Raw, base); err != nil {
+					continue
+				}
+				m := getEmbeddedManifest(base)
+				if m == nil {
+					continue
+				}
+				crd := ctx.GetCRDSchema(m.GVK)
+				if crd == nil || crd.Schema == nil {
+					continue
+				}
+				sctx := scopedContext{
+					linterContext: ctx,
+					entry:         e,
+					basePath:      jsonpath.NewJSONPath(t.Path, "resources", ri, "base", m.Path()),
+				}
+				validateEmbeddedValue(sctx, nil, m.Object, crd.Schema.OpenAPIV3Schema)
+			}
+		}
+	}
+}
+
+// validateEmbeddedValue reports unknown properties and values of the wrong
+// type of value at path. Required properties are not checked, as embedded
+// manifests are often completed by patches.
+func validateEmbeddedValue(ctx scopedContext, path jsonpath.JSONPath, value interface{}, props *extv1.JSONSchemaProps) {
+	if value == nil || props == nil || (pointer.BoolDeref(props.XPreserveUnknownFields, false) && len(props.Properties) == 0) {
+		return
+	}
+	if props.XIntOrString {
+		switch value.(type) {
+		case int64, float64, string:
+		default:
+			ctx.ReportIssueFieldPath(path, fmt.Sprintf(errFmtEmbeddedIntOrString, jsonType(value)), fmt.Sprint(value))
+		}
+		return
+	}
+	switch props.Type {
+	case "object", "":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			if props.Type != "" {
+				ctx.ReportIssueFieldPath(path, fmt.Sprintf(errFieldPathWrongType, "object", jsonType(value)), fmt.Sprint(value))
+			}
+			return
+		}
+		validateEmbeddedObject(ctx, path, obj, props)
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			ctx.ReportIssueFieldPath(path, fmt.Sprintf(errFieldPathWrongType, "array", jsonType(value)), fmt.Sprint(value))
+			return
+		}
+		if props.Items == nil || props.Items.Schema == nil {
+			return
+		}
+		for i, item := range arr {
+			validateEmbeddedValue(ctx, jsonpath.NewJSONPath(path, i), item, props.Items.Schema)
+		}
+	default:
+		if !isOfType(value, props.Type) {
+			ctx.ReportIssueFieldPath(path, fmt.Sprintf(errFieldPathWrongType, props.Type, jsonType(value)), fmt.Sprint(value))
+		}
+	}
+}
+
+func validateEmbeddedObject(ctx scopedContext, path jsonpath.JSONPath, obj map[string]interface{}, props *extv1.JSONSchemaProps) {
+	if len(props.Properties) == 0 && props.AdditionalProperties == nil {
+		// The schema does not restrict the properties.
+		return
+	}
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fieldPath := jsonpath.NewJSONPath(path, name)
+		if prop, exists := props.Properties[name]; exists {
+			validateEmbeddedValue(ctx, fieldPath, obj[name], &prop)
+			continue
+		}
+		if props.AdditionalProperties != nil && props.AdditionalProperties.Allows {
+			validateEmbeddedValue(ctx, fieldPath, obj[name], props.AdditionalProperties.Schema)
+			continue
+		}
+		if pointer.BoolDeref(props.XPreserveUnknownFields, false) {
+			continue
+		}
+		err := &propertyNotFoundError{
+			Field:       name,
+			Suggestions: similarNames(name, propertyNames(props)),
+		}
+		ctx.ReportIssueFieldPath(fieldPath, err.Error(), "")
+	}
+}
+
+// isOfType determines if the JSON value is of the OpenAPI type t.
+func isOfType(value interface{}, t string) bool {
+	switch v := value.(type) {
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case int64, int:
+		return t == "integer" || t == "number"
+	case float64:
+		return t == "number" || (t == "integer" && v == float64(int64(v)))
+	case map[string]interface{}:
+		return t == "object"
+	case []interface{}:
+		return t == "array"
+	}
+	return false
+}
+
+// jsonType returns the OpenAPI type of the JSON value.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64, int:
+		return "integer"
+	case float64:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
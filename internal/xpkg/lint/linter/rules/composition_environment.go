@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"fmt"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtEnvironmentConfigNotFound = "EnvironmentConfig '%s' not found in package"
+	errFmtUnknownEnvironmentSource  = "unknown environment source type, expected '%s' or '%s'"
+	errFmtUnknownLabelMatcherType   = "unknown label matcher type, expected '%s' or '%s'"
+	errEnvironmentPatchType         = "patch type is not supported for environment patches"
+)
+
+// CheckCompositionEnvironment checks the EnvironmentConfig references and the
+// environment patches of compositions.
+func CheckCompositionEnvironment(ctx lint.LinterContext, pkg *xpkg.Package) {
+	configs := map[string]struct{}{}
+	for _, e := range pkg.Entries {
+		if e.IsEnvironmentConfig() {
+			configs[e.Object.GetName()] = struct{}{}
+		}
+	}
+
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		ext, err := e.AsCompositionExtensions()
+		if err != nil || ext.Spec.Environment == nil {
+			// Conversion errors are reported by composition.checkFunctions.
+			continue
+		}
+		compositeGv, err := schema.ParseGroupVersion(comp.Spec.CompositeTypeRef.APIVersion)
+		if err != nil {
+			continue
+		}
+		compositeGvk := compositeGv.WithKind(comp.Spec.CompositeTypeRef.Kind)
+		if ctx.GetCRDSchema(compositeGvk) == nil {
+			// Reported by composition.checkPathFieldPaths.
+			continue
+		}
+		sctx := scopedContext{
+			linterContext: ctx,
+			entry:         e,
+			basePath:      jsonpath.NewJSONPath("spec", "environment"),
+		}
+		for is, source := range ext.Spec.Environment.EnvironmentConfigs {
+			checkEnvironmentSource(sctx.Wrap(jsonpath.NewJSONPath("environmentConfigs", is)), source, configs, compositeGvk)
+		}
+		for ip, p := range ext.Spec.Environment.Patches {
+			checkEnvironmentPatch(sctx.Wrap(jsonpath.NewJSONPath("patches", ip)), p, compositeGvk)
+		}
+	}
+}
+
+func checkEnvironmentSource(ctx scopedContext, source xpkg.EnvironmentSource, configs map[string]struct{}, compositeGvk schema.GroupVersionKind) {
+	switch source.Type {
+	case "", xpkg.EnvironmentSourceTypeReference:
+		if source.Ref == nil || source.Ref.Name == "" {
+			ctx.ReportIssueRequireField(jsonpath.NewJSONPath("ref", "name"))
+			return
+		}
+		if _, exists := configs[source.Ref.Name]; !exists {
+			ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("ref", "name"), fmt.Sprintf(errFmtEnvironmentConfigNotFound, source.Ref.Name), source.Ref.Name)
+		}
+	case xpkg.EnvironmentSourceTypeSelector:
+		if source.Selector == nil {
+			ctx.ReportIssueRequireField(jsonpath.NewJSONPath("selector"))
+			return
+		}
+		for i, m := range source.Selector.MatchLabels {
+			path := jsonpath.NewJSONPath("selector", "matchLabels", i)
+			if m.Key == "" {
+				ctx.ReportIssueRequireField(jsonpath.NewJSONPath(path, "key"))
+			}
+			switch m.Type {
+			case "", xpkg.EnvironmentSelectorLabelTypeFromCompositeFieldPath:
+				if m.ValueFromFieldPath == nil {
+					ctx.ReportIssueRequireField(jsonpath.NewJSONPath(path, "valueFromFieldPath"))
+				} else if err := validateFieldPath(ctx, compositeGvk, *m.ValueFromFieldPath); err != nil {
+					reportInvalidFieldPath(ctx, jsonpath.NewJSONPath(path, "valueFromFieldPath"), compositeGvk, *m.ValueFromFieldPath, err, "")
+				}
+			case xpkg.EnvironmentSelectorLabelTypeValue:
+				if m.Value == nil {
+					ctx.ReportIssueRequireField(jsonpath.NewJSONPath(path, "value"))
+				}
+			default:
+				ctx.ReportIssueFieldPath(jsonpath.NewJSONPath(path, "type"),
+					fmt.Sprintf(errFmtUnknownLabelMatcherType, xpkg.EnvironmentSelectorLabelTypeFromCompositeFieldPath, xpkg.EnvironmentSelectorLabelTypeValue),
+					m.Type)
+			}
+		}
+	default:
+		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("type"),
+			fmt.Sprintf(errFmtUnknownEnvironmentSource, xpkg.EnvironmentSourceTypeReference, xpkg.EnvironmentSourceTypeSelector),
+			source.Type)
+	}
+}
+
+// checkEnvironmentPatch validates a patch between the composite resource and
+// the environment. The environment takes the role of the composed resource.
+func checkEnvironmentPatch(ctx scopedContext, p xpv1.Patch, compositeGvk schema.GroupVersionKind) {
+	switch p.Type {
+	case "", xpv1.PatchTypeFromCompositeFieldPath, xpv1.PatchTypeToCompositeFieldPath,
+		xpv1.PatchTypeCombineFromComposite, xpv1.PatchTypeCombineToComposite:
+		validateResourcePatch(ctx, p, compositeGvk, environmentGvk)
+	default:
+		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("type"), errEnvironmentPatchType, string(p.Type))
+	}
+}
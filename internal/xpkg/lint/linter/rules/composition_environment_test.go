@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+)
+
+// testEnvironmentConfig defines the EnvironmentConfig example.
+const testEnvironmentConfig = `apiVersion: apiextensions.crossplane.io/v1alpha1
+kind: EnvironmentConfig
+metadata:
+  name: example
+data:
+  region: eu-central-1
+`
+
+// testEnvironmentSchema allows the environment field region.
+var testEnvironmentSchema = &extv1.JSONSchemaProps{
+	Type: "object",
+	Properties: map[string]extv1.JSONSchemaProps{
+		"region": {Type: "string"},
+	},
+}
+
+func TestCheckCompositionEnvironment(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		manifests   []string
+		environment *extv1.JSONSchemaProps
+		want        []string
+	}{
+		"Valid": {
+			reason: "References to EnvironmentConfigs of the package and patches of known fields are valid.",
+			manifests: []string{testXRD, testEnvironmentConfig, testComposition(`environment:
+  environmentConfigs:
+  - type: Reference
+    ref:
+      name: example
+  - type: Selector
+    selector:
+      matchLabels:
+      - key: region
+        type: FromCompositeFieldPath
+        valueFromFieldPath: spec.parameters.region
+      - key: stage
+        type: Value
+        value: prod
+  patches:
+  - type: ToCompositeFieldPath
+    fromFieldPath: region
+    toFieldPath: spec.parameters.region
+`, "")},
+			environment: testEnvironmentSchema,
+			want:        []string{},
+		},
+		"NoEnvironment": {
+			reason:    "Compositions without environment are not checked.",
+			manifests: []string{testXRD, testComposition("", "")},
+			want:      []string{},
+		},
+		"UnknownComposite": {
+			reason: "Compositions of unknown composite resources are not checked.",
+			manifests: []string{testComposition(`environment:
+  environmentConfigs:
+  - ref:
+      name: missing
+`, "")},
+			want: []string{},
+		},
+		"EnvironmentConfigNotFound": {
+			reason: "Referenced EnvironmentConfigs must be part of the package.",
+			manifests: []string{testXRD, testComposition(`environment:
+  environmentConfigs:
+  - ref:
+      name: missing
+  - type: Reference
+`, "")},
+			want: []string{
+				"1.yaml .spec.environment.environmentConfigs[0].ref.name: EnvironmentConfig 'missing' not found in package",
+				"1.yaml .spec.environment.environmentConfigs[1].ref.name: require field",
+			},
+		},
+		"InvalidSelector": {
+			reason: "Label matchers of selectors need a key, a value and a known type. Field paths must exist in the composite resource.",
+			manifests: []string{testXRD, testComposition(`environment:
+  environmentConfigs:
+  - type: Selector
+  - type: Selector
+    selector:
+      matchLabels:
+      - valueFromFieldPath: spec.parameters.regoin
+      - key: stage
+        type: Value
+      - key: zone
+        type: Label
+  - type: Name
+`, "")},
+			want: []string{
+				"1.yaml .spec.environment.environmentConfigs[0].selector: require field",
+				"1.yaml .spec.environment.environmentConfigs[1].selector.matchLabels[0].key: require field",
+				"1.yaml .spec.environment.environmentConfigs[1].selector.matchLabels[0].valueFromFieldPath: property 'regoin' not found, did you mean 'region'?",
+				"1.yaml .spec.environment.environmentConfigs[1].selector.matchLabels[1].value: require field",
+				"1.yaml .spec.environment.environmentConfigs[1].selector.matchLabels[2].type: unknown label matcher type, expected 'FromCompositeFieldPath' or 'Value'",
+				"1.yaml .spec.environment.environmentConfigs[2].type: unknown environment source type, expected 'Reference' or 'Selector'",
+			},
+		},
+		"InvalidPatches": {
+			reason: "Environment patches are validated against the composite resource and the environment schema.",
+			manifests: []string{testXRD, testComposition(`environment:
+  patches:
+  - type: FromCompositeFieldPath
+    fromFieldPath: spec.parameters.region
+    toFieldPath: regoin
+  - type: ToCompositeFieldPath
+    fromFieldPath: region
+    toFieldPath: spec.parameters.regoin
+  - type: PatchSet
+    patchSetName: example
+`, "")},
+			environment: testEnvironmentSchema,
+			want: []string{
+				"1.yaml .spec.environment.patches[0].toFieldPath: property 'regoin' not found, did you mean 'region'?",
+				"1.yaml .spec.environment.patches[1].toFieldPath: property 'regoin' not found, did you mean 'region'?",
+				"1.yaml .spec.environment.patches[2].type: patch type is not supported for environment patches",
+			},
+		},
+		"UnknownEnvironmentSchema": {
+			reason: "Any environment field is allowed if no environment schema is configured.",
+			manifests: []string{testXRD, testComposition(`environment:
+  patches:
+  - type: FromCompositeFieldPath
+    fromFieldPath: spec.parameters.region
+    toFieldPath: regoin
+`, "")},
+			want: []string{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := newTestPackage(t, tc.manifests...)
+			store := lintschema.NewSchemaStore()
+			if skipped := store.RegisterPackage(pkg); len(skipped) > 0 {
+				t.Fatalf("RegisterPackage(...): unexpected skipped definitions: %v", skipped)
+			}
+			if tc.environment != nil {
+				store.RegisterEnvironmentSchema(tc.environment)
+			}
+			ctx := &testContext{store: store}
+			CheckCompositionEnvironment(ctx, pkg)
+			if len(ctx.errs) > 0 {
+				t.Fatalf("CheckCompositionEnvironment(...): unexpected errors: %v", ctx.errs)
+			}
+
+			got := formatIssues(ctx.issues)
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nCheckCompositionEnvironment(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
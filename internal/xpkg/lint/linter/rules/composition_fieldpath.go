@@ -1,6 +1,9 @@
 package rules
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
@@ -21,10 +24,23 @@ type scopedContext struct {
 	linterContext lint.LinterContext
 	entry         *xpkg.PackageEntry
 	basePath      jsonpath.JSONPath
+
+	// related are added to all reported issues, for example the resource
+	// that uses a patch set.
+	related []lint.Location
+
+	// schemas override the schemas of the store by GVK, for example with the
+	// schema of a composed resource that embeds a manifest.
+	schemas map[schema.GroupVersionKind]*extv1.JSONSchemaProps
+}
+
+func (c *scopedContext) ReportIssue(issue lint.Issue) {
+	issue.Related = append(issue.Related, c.related...)
+	c.linterContext.ReportIssue(issue)
 }
 
 func (c *scopedContext) ReportIssueRequireField(path jsonpath.JSONPath) {
-	c.linterContext.ReportIssue(lint.Issue{
+	c.ReportIssue(lint.Issue{
 		Entry:       c.entry,
 		Description: "require field",
 		Path:        jsonpath.NewJSONPath(c.basePath, path),
@@ -32,7 +48,7 @@ func (c *scopedContext) ReportIssueRequireField(path jsonpath.JSONPath) {
 }
 
 func (c *scopedContext) ReportIssueFieldPath(path jsonpath.JSONPath, description, pathValue string) {
-	c.linterContext.ReportIssue(lint.Issue{
+	c.ReportIssue(lint.Issue{
 		Entry:       c.entry,
 		Description: description,
 		Path:        jsonpath.NewJSONPath(c.basePath, path),
@@ -45,6 +61,8 @@ func (c *scopedContext) Wrap(path jsonpath.JSONPath) scopedContext {
 		entry:         c.entry,
 		linterContext: c.linterContext,
 		basePath:      jsonpath.NewJSONPath(c.basePath, path),
+		related:       c.related,
+		schemas:       c.schemas,
 	}
 }
 
@@ -52,17 +70,71 @@ func CheckCompositionFieldPaths(ctx lint.LinterContext, pkg *xpkg.Package) {
 	wg := sync.WaitGroup{}
 	wg.Add(len(pkg.Entries))
 
+	missing := newMissingSchemas()
 	for _, m := range pkg.Entries {
 		manifest := m
 		go func() {
-			checkCompositionFieldPaths(ctx, pkg, manifest)
+			checkCompositionFieldPaths(ctx, pkg, manifest, missing)
 			wg.Done()
 		}()
 	}
 	wg.Wait()
+	missing.report(ctx)
+}
+
+// missingSchemas collects the locations that need a schema that is unknown to
+// the linter, so each missing schema is reported once.
+type missingSchemas struct {
+	mu        sync.Mutex
+	locations map[schema.GroupVersionKind][]lint.Location
+}
+
+func newMissingSchemas() *missingSchemas {
+	return &missingSchemas{locations: map[schema.GroupVersionKind][]lint.Location{}}
 }
 
-func checkCompositionFieldPaths(ctx lint.LinterContext, pkg *xpkg.Package, manifest xpkg.PackageEntry) {
+func (m *missingSchemas) add(gvk schema.GroupVersionKind, e *xpkg.PackageEntry, path jsonpath.JSONPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locations[gvk] = append(m.locations[gvk], lint.Location{Entry: e, Path: path})
+}
+
+// report an issue for each missing schema. The issue points to the first
+// location that needs the schema and lists the other ones as related.
+func (m *missingSchemas) report(ctx lint.LinterContext) {
+	gvks := make([]schema.GroupVersionKind, 0, len(m.locations))
+	for gvk := range m.locations {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+	for _, gvk := range gvks {
+		locations := m.locations[gvk]
+		sort.SliceStable(locations, func(i, j int) bool {
+			if locations[i].Entry.Source != locations[j].Entry.Source {
+				return locations[i].Entry.Source < locations[j].Entry.Source
+			}
+			return locations[i].Path.String() < locations[j].Path.String()
+		})
+		names := []string{}
+		seen := map[string]bool{}
+		for _, l := range locations {
+			if name := l.Entry.Object.GetName(); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		ctx.ReportIssue(lint.Issue{
+			Entry:       locations[0].Entry,
+			Path:        locations[0].Path,
+			Related:     locations[1:],
+			Description: fmt.Sprintf(errFmtNoCRDNeededBy, gvk.String(), strings.Join(names, ", ")),
+		})
+	}
+}
+
+func checkCompositionFieldPaths(ctx lint.LinterContext, pkg *xpkg.Package, manifest xpkg.PackageEntry, missing *missingSchemas) {
 	if !manifest.IsComposition() {
 		return
 	}
@@ -85,20 +157,22 @@ func checkCompositionFieldPaths(ctx lint.LinterContext, pkg *xpkg.Package, manif
 	compositeGvk := compositeGv.WithKind(comp.Spec.CompositeTypeRef.Kind)
 	compositeCRD := ctx.GetCRDSchema(compositeGvk)
 	if compositeCRD == nil {
-		ctx.ReportIssue(lint.Issue{
-			Entry:       &manifest,
-			Path:        jsonpath.NewJSONPath("spec", "compositeTypeRef"),
-			Description: errors.Errorf(errNoCRDForGVK, compositeGvk.String()).Error(),
-		})
+		missing.add(compositeGvk, &manifest, jsonpath.NewJSONPath("spec", "compositeTypeRef"))
 		return
 	}
 
-	for i, r := range comp.Spec.Resources {
+	for _, t := range getComposedTemplates(&manifest, comp) {
+		checkComposedTemplatesFieldPaths(ctx, &manifest, t, compositeGvk, missing)
+	}
+}
+
+func checkComposedTemplatesFieldPaths(ctx lint.LinterContext, manifest *xpkg.PackageEntry, t composedTemplates, compositeGvk schema.GroupVersionKind, missing *missingSchemas) {
+	for i, r := range t.Composition.Spec.Resources {
 		base := &unstructured.Unstructured{}
 		if err := yaml.Unmarshal(r.Base.Raw, base); err != nil {
 			ctx.ReportIssue(lint.Issue{
-				Entry:       &manifest,
-				Path:        jsonpath.NewJSONPath("spec", "resources", i, "base"),
+				Entry:       manifest,
+				Path:        jsonpath.NewJSONPath(t.Path, "resources", i, "base"),
 				Description: errors.Wrap(err, "failed to parse base").Error(),
 			})
 			continue
@@ -106,34 +180,35 @@ func checkCompositionFieldPaths(ctx lint.LinterContext, pkg *xpkg.Package, manif
 		baseGvk := base.GetObjectKind().GroupVersionKind()
 		baseCrd := ctx.GetCRDSchema(baseGvk)
 		if baseCrd == nil {
-			ctx.ReportIssue(lint.Issue{
-				Entry:       &manifest,
-				Path:        jsonpath.NewJSONPath("spec", "resources", i, "base"),
-				Description: errors.Errorf(errNoCRDForGVK, baseGvk.String()).Error(),
-			})
+			missing.add(baseGvk, manifest, jsonpath.NewJSONPath(t.Path, "resources", i, "base"))
 			continue
 		}
+		// Field paths into an embedded manifest are validated against the
+		// schema of its kind.
+		var schemas map[schema.GroupVersionKind]*extv1.JSONSchemaProps
+		if m := getEmbeddedManifest(base); m != nil {
+			if ctx.GetCRDSchema(m.GVK) == nil {
+				missing.add(m.GVK, manifest, jsonpath.NewJSONPath(t.Path, "resources", i, "base", m.Path()))
+			} else {
+				schemas = map[schema.GroupVersionKind]*extv1.JSONSchemaProps{
+					baseGvk: composedResourceSchema(ctx, base, baseCrd.Schema.OpenAPIV3Schema),
+				}
+			}
+		}
 		for ip, p := range r.Patches {
 			sctx := scopedContext{
 				linterContext: ctx,
-				entry:         &manifest,
-				basePath:      jsonpath.NewJSONPath("spec", "resources", i, "patches", ip),
+				entry:         manifest,
+				basePath:      jsonpath.NewJSONPath(t.Path, "resources", i, "patches", ip),
+				schemas:       schemas,
 			}
-			validatePatch(sctx, p, comp, compositeGvk, baseGvk)
+			validatePatch(sctx, p, t, compositeGvk, baseGvk)
 		}
 	}
 }
 
-func validatePatch(ctx scopedContext, p xpv1.Patch, comp *xpv1.Composition, compositeGvk, baseGvk schema.GroupVersionKind) {
+func validatePatch(ctx scopedContext, p xpv1.Patch, t composedTemplates, compositeGvk, baseGvk schema.GroupVersionKind) {
 	switch p.Type {
-	case xpv1.PatchTypeCombineToComposite:
-		validateCombinePatch(ctx, p, baseGvk, compositeGvk)
-	case xpv1.PatchTypeCombineFromComposite:
-		validateCombinePatch(ctx, p, compositeGvk, baseGvk)
-	case xpv1.PatchTypeToCompositeFieldPath:
-		validateSinglePatch(ctx, p, baseGvk, compositeGvk)
-	case "", xpv1.PatchTypeFromCompositeFieldPath:
-		validateSinglePatch(ctx, p, compositeGvk, baseGvk)
 	case xpv1.PatchTypePatchSet:
 		if p.PatchSetName == nil {
 			ctx.ReportIssueRequireField(jsonpath.NewJSONPath("patchSetName"))
@@ -141,7 +216,7 @@ func validatePatch(ctx scopedContext, p xpv1.Patch, comp *xpv1.Composition, comp
 		}
 		var patchSet *xpv1.PatchSet
 		var patchSetIndex int
-		for i, s := range comp.Spec.PatchSets {
+		for i, s := range t.Composition.Spec.PatchSets {
 			if s.Name == *p.PatchSetName {
 				patchSet = &s
 				patchSetIndex = i
@@ -152,7 +227,41 @@ func validatePatch(ctx scopedContext, p xpv1.Patch, comp *xpv1.Composition, comp
 			ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("patchSetName"), "no matching patchset found", *p.PatchSetName)
 			break
 		}
-		validatePatchSet(ctx, *patchSet, compositeGvk, baseGvk, jsonpath.NewJSONPath("#inlined", "spec", "patchSets", patchSetIndex))
+		// Issues point to the patch set, so resources using the same broken
+		// patch set are reported once.
+		psctx := scopedContext{
+			linterContext: ctx.linterContext,
+			entry:         ctx.entry,
+			basePath:      jsonpath.NewJSONPath(t.Path, "patchSets", patchSetIndex),
+			related:       []lint.Location{{Entry: ctx.entry, Path: ctx.basePath}},
+			schemas:       ctx.schemas,
+		}
+		validatePatchSet(psctx, *patchSet, compositeGvk, baseGvk)
+	default:
+		validateResourcePatch(ctx, p, compositeGvk, baseGvk)
+	}
+}
+
+// validateResourcePatch validates the field paths of p which is a patch of a
+// composed resource that is not of type PatchSet.
+func validateResourcePatch(ctx scopedContext, p xpv1.Patch, compositeGvk, baseGvk schema.GroupVersionKind) {
+	switch p.Type {
+	case xpv1.PatchTypeCombineToComposite:
+		validateCombinePatch(ctx, p, baseGvk, compositeGvk)
+	case xpv1.PatchTypeCombineFromComposite:
+		validateCombinePatch(ctx, p, compositeGvk, baseGvk)
+	case xpv1.PatchTypeToCompositeFieldPath:
+		validateSinglePatch(ctx, p, baseGvk, compositeGvk)
+	case "", xpv1.PatchTypeFromCompositeFieldPath:
+		validateSinglePatch(ctx, p, compositeGvk, baseGvk)
+	case xpkg.PatchTypeCombineToEnvironment:
+		validateCombinePatch(ctx, p, baseGvk, environmentGvk)
+	case xpkg.PatchTypeCombineFromEnvironment:
+		validateCombinePatch(ctx, p, environmentGvk, baseGvk)
+	case xpkg.PatchTypeToEnvironmentFieldPath:
+		validateSinglePatch(ctx, p, baseGvk, environmentGvk)
+	case xpkg.PatchTypeFromEnvironmentFieldPath:
+		validateSinglePatch(ctx, p, environmentGvk, baseGvk)
 	default:
 		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("type"), "unknown patch type", string(p.Type))
 	}
@@ -168,16 +277,27 @@ func validateCombinePatch(ctx scopedContext, p xpv1.Patch, fromGvk, toGvk schema
 				continue
 			}
 			if err := validateFieldPath(ctx, fromGvk, v.FromFieldPath); err != nil {
-				ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("combine", "variables", i, "fromFieldPath"), err.Error(), v.FromFieldPath)
+				reportInvalidFieldPath(ctx, jsonpath.NewJSONPath("combine", "variables", i, "fromFieldPath"), fromGvk, v.FromFieldPath, err, "")
 			}
 		}
 	}
 	if p.ToFieldPath == nil {
-		ctx.ReportIssueRequireField(jsonpath.NewJSONPath("toFieldPath"))
+		issue := lint.Issue{
+			Entry:       ctx.entry,
+			Description: "require field",
+			Path:        jsonpath.NewJSONPath(ctx.basePath, "toFieldPath"),
+		}
+		// A single variable is likely combined into the same path if the
+		// target has it. This is a guess, so it is not fixed.
+		if p.Combine != nil && len(p.Combine.Variables) == 1 && p.Combine.Variables[0].FromFieldPath != "" &&
+			validateFieldPath(ctx, toGvk, p.Combine.Variables[0].FromFieldPath) == nil {
+			issue.Suggestions = []string{p.Combine.Variables[0].FromFieldPath}
+		}
+		ctx.ReportIssue(issue)
 		return
 	}
 	if err := validateFieldPath(ctx, toGvk, *p.ToFieldPath); err != nil {
-		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("toFieldPath"), err.Error(), *p.ToFieldPath)
+		reportInvalidFieldPath(ctx, jsonpath.NewJSONPath("toFieldPath"), toGvk, *p.ToFieldPath, err, "")
 	}
 }
 
@@ -185,7 +305,7 @@ func validateSinglePatch(ctx scopedContext, p xpv1.Patch, fromGvk, toGvk schema.
 	if p.FromFieldPath == nil {
 		ctx.ReportIssueRequireField(jsonpath.NewJSONPath("fromFieldPath"))
 	} else if err := validateFieldPath(ctx, fromGvk, *p.FromFieldPath); err != nil {
-		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("fromFieldPath"), err.Error(), *p.FromFieldPath)
+		reportInvalidFieldPath(ctx, jsonpath.NewJSONPath("fromFieldPath"), fromGvk, *p.FromFieldPath, err, "")
 	}
 	toFieldPath := p.ToFieldPath
 	if toFieldPath == nil {
@@ -194,60 +314,237 @@ func validateSinglePatch(ctx scopedContext, p xpv1.Patch, fromGvk, toGvk schema.
 	if toFieldPath == nil {
 		ctx.ReportIssueRequireField(jsonpath.NewJSONPath("toFieldPath"))
 	} else if err := validateFieldPath(ctx, toGvk, *toFieldPath); err != nil {
-		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("toFieldPath"), err.Error(), *toFieldPath)
+		reportInvalidFieldPath(ctx, jsonpath.NewJSONPath("toFieldPath"), toGvk, *toFieldPath, err, implicitKey(p.ToFieldPath, "toFieldPath"))
 	}
 }
 
-func validatePatchSet(ctx scopedContext, ps xpv1.PatchSet, compositeGvk, baseGvk schema.GroupVersionKind, patchPath jsonpath.JSONPath) {
+// implicitKey returns key if value is nil, so the implicit value is fixed by
+// adding key explicitly. Returns an empty string otherwise.
+func implicitKey(value *string, key string) string {
+	if value != nil {
+		return ""
+	}
+	return key
+}
+
+// reportInvalidFieldPath reports err of the field path rawPath at path
+// together with similar valid field paths. Attaches a fix if there is only
+// one and it only corrects case or single typos. If implicitKey is set,
+// rawPath is implicit and fixed by adding the field implicitKey to the patch.
+func reportInvalidFieldPath(ctx scopedContext, path jsonpath.JSONPath, gvk schema.GroupVersionKind, rawPath string, err error, implicitKey string) {
+	issue := lint.Issue{
+		Entry:       ctx.entry,
+		Description: err.Error(),
+		Path:        jsonpath.NewJSONPath(ctx.basePath, path),
+		PathValue:   rawPath,
+	}
+	if inspector, ok := ctx.linterContext.(lint.SchemaInspector); ok {
+		if origin := inspector.GetSchemaOrigin(gvk); origin != nil {
+			issue.Schema = origin.String()
+		}
+	}
+	suggestions := suggestFieldPaths(fieldPathRootSchema(ctx, gvk), rawPath)
+	if len(suggestions) == 1 && isTightFieldPath(rawPath, suggestions[0]) {
+		corrected := suggestions[0]
+		fix := lint.Fix{
+			Type:        lint.FixTypeSetValue,
+			Description: fmt.Sprintf(errFmtFixFieldPath, rawPath, corrected),
+			Path:        issue.Path,
+			Value:       corrected,
+		}
+		if implicitKey != "" {
+			fix.Type = lint.FixTypeAddField
+			fix.Path = ctx.basePath
+			fix.Key = implicitKey
+		}
+		issue.Fixes = []lint.Fix{fix}
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	issue.Suggestions = suggestions
+	ctx.ReportIssue(issue)
+}
+
+func validatePatchSet(ctx scopedContext, ps xpv1.PatchSet, compositeGvk, baseGvk schema.GroupVersionKind) {
 	for i, p := range ps.Patches {
-		sctx := ctx.Wrap(jsonpath.NewJSONPath(patchPath, "patches", i, "type"))
-		switch p.Type {
-		case xpv1.PatchTypeCombineToComposite:
-			validateCombinePatch(sctx, p, baseGvk, compositeGvk)
-		case xpv1.PatchTypeCombineFromComposite:
-			validateCombinePatch(sctx, p, compositeGvk, baseGvk)
-		case xpv1.PatchTypeToCompositeFieldPath:
-			validateSinglePatch(sctx, p, baseGvk, compositeGvk)
-		case "", xpv1.PatchTypeFromCompositeFieldPath:
-			validateSinglePatch(sctx, p, compositeGvk, baseGvk)
-		case xpv1.PatchTypePatchSet:
+		sctx := ctx.Wrap(jsonpath.NewJSONPath("patches", i))
+		if p.Type == xpv1.PatchTypePatchSet {
 			sctx.ReportIssueFieldPath(jsonpath.NewJSONPath("type"), "nested patch sets are not allowed", string(p.Type))
-		default:
-			sctx.ReportIssueFieldPath(jsonpath.NewJSONPath("type"), "unknown patch type", string(p.Type))
+			continue
 		}
+		validateResourcePatch(sctx, p, compositeGvk, baseGvk)
 	}
 }
 
 const (
-	errValidateFieldPath  = "failed to validate segment %d"
-	errNoCRDForGVK        = "no CRD for %s"
-	errFieldPathWrongType = "expected type '%s' but got '%s'"
-	errFieldNotFound      = "property '%s' not found"
-	errFieldArrayNoItems  = "prop type is array but missing items definition"
-	errInvalidSegmentType = "invalid segment type %d"
+	errFmtFixFieldPath = "replace '%s' with '%s'"
+
+	errNoCRDForGVK             = "no CRD for %s"
+	errFmtNoCRDNeededBy        = "no CRD for %s, needed by compositions %s"
+	errFieldPathWrongType      = "expected type '%s' but got '%s'"
+	errFieldNotFound           = "property '%s' not found"
+	errFmtFieldNotFoundSuggest = "property '%s' not found, did you mean %s?"
+	errFieldArrayNoItems       = "prop type is array but missing items definition"
+	errInvalidSegmentType      = "invalid segment type %d"
 )
 
+// environmentGvk identifies the environment of a composition as source or
+// target of a patch. It is validated against the environment schema if one is
+// configured.
+var environmentGvk = schema.GroupVersionKind{Group: "internal.crossplane-lint.io", Version: "v1", Kind: "Environment"}
+
 func validateFieldPath(ctx scopedContext, gvk schema.GroupVersionKind, rawPath string) error {
 	path, err := fieldpath.Parse(rawPath)
 	if err != nil {
 		return err
 	}
-	crd := ctx.linterContext.GetCRDSchema(gvk)
-	if crd == nil {
+	root := fieldPathRootSchema(ctx, gvk)
+	if root == nil && gvk != environmentGvk {
 		return errors.Errorf(errNoCRDForGVK, gvk.String())
 	}
-	current := crd.Schema.OpenAPIV3Schema
-	for _, segment := range path {
-		if current == nil {
-			return nil
+	_, err = resolveFieldPathSchemas(root, path)
+	return err
+}
+
+// fieldPathRootSchema returns the schema field paths of gvk are resolved
+// against or nil if it is unknown.
+func fieldPathRootSchema(ctx scopedContext, gvk schema.GroupVersionKind) *extv1.JSONSchemaProps {
+	if gvk == environmentGvk {
+		return ctx.linterContext.GetEnvironmentSchema()
+	}
+	if props, exists := ctx.schemas[gvk]; exists {
+		return props
+	}
+	crd := ctx.linterContext.GetCRDSchema(gvk)
+	if crd == nil || crd.Schema == nil {
+		return nil
+	}
+	return crd.Schema.OpenAPIV3Schema
+}
+
+// suggestFieldPaths returns all valid field paths that are similar to
+// rawPath. Each unknown field of rawPath is replaced with the properties of
+// its parent that are similar to it.
+func suggestFieldPaths(root *extv1.JSONSchemaProps, rawPath string) []string {
+	path, err := fieldpath.Parse(rawPath)
+	if err != nil || root == nil {
+		return nil
+	}
+	suggestions := []string{}
+	collectFieldPathSuggestions(root, path, 0, &suggestions)
+	if len(suggestions) == 1 && suggestions[0] == rawPath {
+		// rawPath is valid.
+		return nil
+	}
+	return suggestions
+}
+
+// isTightFieldPath returns true if the field path corrected only differs from
+// rawPath in fields that are tight matches, see isTightMatch.
+func isTightFieldPath(rawPath, corrected string) bool {
+	path, err := fieldpath.Parse(rawPath)
+	if err != nil {
+		return false
+	}
+	correctedPath, err := fieldpath.Parse(corrected)
+	if err != nil || len(path) != len(correctedPath) {
+		return false
+	}
+	for i := range path {
+		if path[i].Field != correctedPath[i].Field && !isTightMatch(path[i].Field, correctedPath[i].Field) {
+			return false
 		}
+	}
+	return true
+}
+
+// collectFieldPathSuggestions resolves path from segment i on starting at
+// current and adds it to suggestions if it is valid. Unknown fields are
+// replaced with each similar property.
+func collectFieldPathSuggestions(current *extv1.JSONSchemaProps, path fieldpath.Segments, i int, suggestions *[]string) {
+	for ; i < len(path); i++ {
+		next, err := validateFieldPathSegment(current, path[i])
+		if err != nil {
+			notFound := &propertyNotFoundError{}
+			if !errors.As(err, &notFound) {
+				return
+			}
+			for _, name := range allSimilarNames(notFound.Field, propertyNames(current)) {
+				alt := append(fieldpath.Segments{}, path...)
+				alt[i].Field = name
+				prop := current.Properties[name]
+				collectFieldPathSuggestions(&prop, alt, i+1, suggestions)
+			}
+			return
+		}
+		if next == nil {
+			// Fields with unknown schema are valid.
+			break
+		}
+		current = next
+	}
+	*suggestions = append(*suggestions, path.String())
+}
+
+func propertyNames(props *extv1.JSONSchemaProps) []string {
+	names := make([]string, 0, len(props.Properties))
+	for name := range props.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveFieldPath resolves the schema of each segment of rawPath starting at
+// root. See resolveFieldPathSchemas.
+func ResolveFieldPath(root *extv1.JSONSchemaProps, rawPath string) ([]*extv1.JSONSchemaProps, error) {
+	path, err := fieldpath.Parse(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	return resolveFieldPathSchemas(root, path)
+}
+
+// resolveFieldPathSchemas resolves the schema of each segment of path starting
+// at root. The first element of the result is root. The result is shorter than
+// path if a segment points into a field with unknown schema. Returns nil if
+// root is nil.
+func resolveFieldPathSchemas(root *extv1.JSONSchemaProps, path fieldpath.Segments) ([]*extv1.JSONSchemaProps, error) {
+	if root == nil {
+		return nil, nil
+	}
+	schemas := []*extv1.JSONSchemaProps{root}
+	current := root
+	for _, segment := range path {
 		var err error
 		current, err = validateFieldPathSegment(current, segment)
-		if err != nil { // Workaround for now
-			return err
+		if err != nil {
+			return schemas, err
+		}
+		if current == nil {
+			return schemas, nil
 		}
+		schemas = append(schemas, current)
 	}
-	return nil
+	return schemas, nil
+}
+
+// propertyNotFoundError is returned for fields of a field path that are not
+// a property of their parent.
+type propertyNotFoundError struct {
+	Field string
+
+	// Suggestions are the properties of the parent that are similar to
+	// Field.
+	Suggestions []string
+}
+
+func (e *propertyNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf(errFieldNotFound, e.Field)
+	}
+	return fmt.Sprintf(errFmtFieldNotFoundSuggest, e.Field, formatSuggestions(e.Suggestions))
 }
 
 func validateFieldPathSegment(current *extv1.JSONSchemaProps, segment fieldpath.Segment) (*extv1.JSONSchemaProps, error) {
@@ -268,7 +565,10 @@ func validateFieldPathSegment(current *extv1.JSONSchemaProps, segment fieldpath.
 			if current.AdditionalProperties != nil && current.AdditionalProperties.Allows {
 				return current.AdditionalProperties.Schema, nil
 			}
-			return nil, errors.Errorf(errFieldNotFound, segment.Field)
+			return nil, &propertyNotFoundError{
+				Field:       segment.Field,
+				Suggestions: similarNames(segment.Field, propertyNames(current)),
+			}
 		}
 		return &prop, nil
 	case fieldpath.SegmentIndex:
@@ -0,0 +1,177 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtInvalidCompositionMode  = "invalid mode, expected '%s' or '%s'"
+	errFmtPipelineRequired        = "pipeline must not be empty in mode '%s'"
+	errFmtPipelineWithoutMode     = "pipeline requires mode '%s'"
+	errFmtResourcesInPipelineMode = "resources are not supported in mode '%s'"
+	errFmtDuplicateStep           = "step name is already used by step %d"
+	errFmtFunctionNotFound        = "function '%s' not found in package or dependencies"
+	errFmtDuplicateFunction       = "function name is already used by function %d"
+	errFmtUnknownFunctionType     = "unknown function type, expected '%s'"
+	errParsePatchAndTransform     = "failed to parse function-patch-and-transform input"
+)
+
+// CheckCompositionFunctions checks the function pipeline and the alpha
+// container functions of compositions.
+func CheckCompositionFunctions(ctx lint.LinterContext, pkg *xpkg.Package) {
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		ext, err := e.AsCompositionExtensions()
+		if err != nil {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Description: errors.Wrapf(err, errConvertTo, "Composition").Error(),
+			})
+			continue
+		}
+		checkCompositionMode(ctx, e, ext, len(comp.Spec.Resources) > 0)
+		checkPipeline(ctx, e, ext.Spec.Pipeline)
+		checkContainerFunctions(ctx, e, ext.Spec.Functions)
+	}
+}
+
+func checkCompositionMode(ctx lint.LinterContext, e *xpkg.PackageEntry, ext *xpkg.CompositionExtensions, hasResources bool) {
+	mode := xpkg.CompositionModeResources
+	if ext.Spec.Mode != nil {
+		mode = *ext.Spec.Mode
+	}
+	switch mode {
+	case xpkg.CompositionModeResources:
+		if len(ext.Spec.Pipeline) > 0 {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath("spec", "pipeline"),
+				Description: fmt.Sprintf(errFmtPipelineWithoutMode, xpkg.CompositionModePipeline),
+			})
+		}
+	case xpkg.CompositionModePipeline:
+		if len(ext.Spec.Pipeline) == 0 {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath("spec", "mode"),
+				PathValue:   mode,
+				Description: fmt.Sprintf(errFmtPipelineRequired, mode),
+			})
+		}
+		if hasResources {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath("spec", "resources"),
+				Description: fmt.Sprintf(errFmtResourcesInPipelineMode, mode),
+			})
+		}
+	default:
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        jsonpath.NewJSONPath("spec", "mode"),
+			PathValue:   mode,
+			Description: fmt.Sprintf(errFmtInvalidCompositionMode, xpkg.CompositionModeResources, xpkg.CompositionModePipeline),
+		})
+	}
+}
+
+func checkPipeline(ctx lint.LinterContext, e *xpkg.PackageEntry, pipeline []xpkg.PipelineStep) {
+	steps := map[string]int{}
+	for i, step := range pipeline {
+		path := jsonpath.NewJSONPath("spec", "pipeline", i)
+		if step.Step == "" {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        path,
+				Description: "require field step",
+			})
+		} else if first, exists := steps[step.Step]; exists {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath(path, "step"),
+				PathValue:   step.Step,
+				Description: fmt.Sprintf(errFmtDuplicateStep, first),
+			})
+		} else {
+			steps[step.Step] = i
+		}
+
+		switch {
+		case step.FunctionRef.Name == "":
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        path,
+				Description: "require field functionRef.name",
+			})
+		case !ctx.HasFunction(step.FunctionRef.Name):
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath(path, "functionRef", "name"),
+				PathValue:   step.FunctionRef.Name,
+				Description: fmt.Sprintf(errFmtFunctionNotFound, step.FunctionRef.Name),
+			})
+		}
+
+		if step.IsPatchAndTransformInput() {
+			if _, err := step.AsPatchAndTransformInput(); err != nil {
+				ctx.ReportIssue(lint.Issue{
+					Entry:       e,
+					Path:        jsonpath.NewJSONPath(path, "input"),
+					Description: errors.Wrap(err, errParsePatchAndTransform).Error(),
+				})
+			}
+		}
+	}
+}
+
+func checkContainerFunctions(ctx lint.LinterContext, e *xpkg.PackageEntry, functions []xpkg.ContainerFunction) {
+	names := map[string]int{}
+	for i, fn := range functions {
+		path := jsonpath.NewJSONPath("spec", "functions", i)
+		if fn.Name == "" {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        path,
+				Description: "require field name",
+			})
+		} else if first, exists := names[fn.Name]; exists {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath(path, "name"),
+				PathValue:   fn.Name,
+				Description: fmt.Sprintf(errFmtDuplicateFunction, first),
+			})
+		} else {
+			names[fn.Name] = i
+		}
+
+		if fn.Type != xpkg.FunctionTypeContainer {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath(path, "type"),
+				PathValue:   fn.Type,
+				Description: fmt.Sprintf(errFmtUnknownFunctionType, xpkg.FunctionTypeContainer),
+			})
+		} else if fn.Container == nil || fn.Container.Image == "" {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        path,
+				Description: "require field container.image",
+			})
+		}
+	}
+}
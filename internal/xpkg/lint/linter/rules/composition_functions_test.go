@@ -0,0 +1,132 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testFunction defines the Function function-patch-and-transform.
+const testFunction = `apiVersion: pkg.crossplane.io/v1beta1
+kind: Function
+metadata:
+  name: function-patch-and-transform
+spec:
+  package: xpkg.upbound.io/crossplane-contrib/function-patch-and-transform:v0.1.4
+`
+
+const testBucketResource = `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+`
+
+func TestCheckCompositionFunctions(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		manifests []string
+		want      []string
+	}{
+		"Resources": {
+			reason:    "Compositions in the default mode without functions are valid.",
+			manifests: []string{testXRD, testCRD, testComposition("", testBucketResource)},
+			want:      []string{},
+		},
+		"Pipeline": {
+			reason: "Pipelines of known functions with valid inputs are valid.",
+			manifests: []string{testXRD, testFunction, testComposition(`mode: Pipeline
+pipeline:
+- step: patch-and-transform
+  functionRef:
+    name: function-patch-and-transform
+  input:
+    apiVersion: pt.fn.crossplane.io/v1beta1
+    kind: Resources
+    resources: []
+`, "")},
+			want: []string{},
+		},
+		"PipelineWithoutMode": {
+			reason: "Pipelines are only run in mode Pipeline.",
+			manifests: []string{testXRD, testFunction, testComposition(`pipeline:
+- step: patch-and-transform
+  functionRef:
+    name: function-patch-and-transform
+`, "")},
+			want: []string{"2.yaml .spec.pipeline: pipeline requires mode 'Pipeline'"},
+		},
+		"EmptyPipeline": {
+			reason:    "Mode Pipeline requires a pipeline and does not support resources.",
+			manifests: []string{testXRD, testCRD, testComposition("mode: Pipeline\n", testBucketResource)},
+			want: []string{
+				"2.yaml .spec.mode: pipeline must not be empty in mode 'Pipeline'",
+				"2.yaml .spec.resources: resources are not supported in mode 'Pipeline'",
+			},
+		},
+		"InvalidMode": {
+			reason:    "Only the modes Resources and Pipeline are supported.",
+			manifests: []string{testXRD, testComposition("mode: Functions\n", "")},
+			want:      []string{"1.yaml .spec.mode: invalid mode, expected 'Resources' or 'Pipeline'"},
+		},
+		"InvalidSteps": {
+			reason: "Steps need unique names and must reference functions of the package or its dependencies.",
+			manifests: []string{testXRD, testFunction, testComposition(`mode: Pipeline
+pipeline:
+- step: a
+  functionRef:
+    name: function-patch-and-transform
+- step: a
+  functionRef:
+    name: function-missing
+- functionRef:
+    name: ""
+`, "")},
+			want: []string{
+				"2.yaml .spec.pipeline[1].functionRef.name: function 'function-missing' not found in package or dependencies",
+				"2.yaml .spec.pipeline[1].step: step name is already used by step 0",
+				"2.yaml .spec.pipeline[2]: require field functionRef.name",
+				"2.yaml .spec.pipeline[2]: require field step",
+			},
+		},
+		"InvalidPatchAndTransformInput": {
+			reason: "Inputs of function-patch-and-transform must be valid.",
+			manifests: []string{testXRD, testFunction, testComposition(`mode: Pipeline
+pipeline:
+- step: patch-and-transform
+  functionRef:
+    name: function-patch-and-transform
+  input:
+    apiVersion: pt.fn.crossplane.io/v1beta1
+    kind: Resources
+    resources: invalid
+`, "")},
+			want: []string{"2.yaml .spec.pipeline[0].input: failed to parse function-patch-and-transform input: failed to convert object to PatchAndTransformInput: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal string into Go struct field .resources of type []v1.ComposedTemplate"},
+		},
+		"ContainerFunctions": {
+			reason: "Container functions need unique names, type Container and an image.",
+			manifests: []string{testXRD, testCRD, testComposition(`functions:
+- name: a
+  type: Container
+  container:
+    image: example.org/function:v1
+- name: a
+  type: Container
+- type: Webhook
+`, testBucketResource)},
+			want: []string{
+				"2.yaml .spec.functions[1].name: function name is already used by function 0",
+				"2.yaml .spec.functions[1]: require field container.image",
+				"2.yaml .spec.functions[2].type: unknown function type, expected 'Container'",
+				"2.yaml .spec.functions[2]: require field name",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := newTestPackage(t, tc.manifests...)
+			got := formatIssues(runRule(t, CheckCompositionFunctions, pkg))
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nCheckCompositionFunctions(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
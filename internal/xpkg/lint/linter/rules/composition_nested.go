@@ -0,0 +1,374 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtComposedClaim          = "claim kind '%s' of XRD '%s' cannot be composed, compose the composite kind '%s' instead"
+	errFmtFixComposeComposite    = "replace with '%s'"
+	errFmtCompositionNotFound    = "composition '%s' not found in package"
+	errFmtCompositionWrongType   = "composition '%s' implements %s, not %s"
+	errFmtCompositionCycle       = "compositions compose each other in a cycle that never ends: %s"
+	errFmtCompositionMayCycle    = "compositions may compose each other in a cycle that never ends, depending on the selected compositions: %s"
+	errFmtCompositionCycleMember = "%s (%s)"
+)
+
+// packageXRDs are the XRDs of a package by the group kinds of their composite
+// resources and claims.
+type packageXRDs struct {
+	composites map[schema.GroupKind]*xpv1.CompositeResourceDefinition
+	claims     map[schema.GroupKind]*xpv1.CompositeResourceDefinition
+}
+
+func getPackageXRDs(pkg *xpkg.Package) packageXRDs {
+	xrds := packageXRDs{
+		composites: map[schema.GroupKind]*xpv1.CompositeResourceDefinition{},
+		claims:     map[schema.GroupKind]*xpv1.CompositeResourceDefinition{},
+	}
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsXRD() {
+			continue
+		}
+		xrd, err := e.AsXRD()
+		if err != nil {
+			continue
+		}
+		xrds.composites[schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.Names.Kind}] = xrd
+		if xrd.Spec.ClaimNames != nil {
+			xrds.claims[schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.ClaimNames.Kind}] = xrd
+		}
+	}
+	return xrds
+}
+
+// packageComposition is a Composition of a package.
+type packageComposition struct {
+	Entry       *xpkg.PackageEntry
+	Composition *xpv1.Composition
+
+	// CompositeType is the group kind of compositeTypeRef.
+	CompositeType schema.GroupKind
+}
+
+// getPackageCompositions returns the compositions of pkg sorted by source and
+// name. Entries are parsed concurrently, so issues that depend on the order
+// of compositions are only reported at the same location on every run if
+// they are sorted.
+func getPackageCompositions(pkg *xpkg.Package) []packageComposition {
+	comps := []packageComposition{}
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(comp.Spec.CompositeTypeRef.APIVersion)
+		if err != nil {
+			continue
+		}
+		comps = append(comps, packageComposition{
+			Entry:         e,
+			Composition:   comp,
+			CompositeType: gv.WithKind(comp.Spec.CompositeTypeRef.Kind).GroupKind(),
+		})
+	}
+	sort.SliceStable(comps, func(i, j int) bool {
+		if comps[i].Entry.Source != comps[j].Entry.Source {
+			return comps[i].Entry.Source < comps[j].Entry.Source
+		}
+		return comps[i].Composition.GetName() < comps[j].Composition.GetName()
+	})
+	return comps
+}
+
+// selectCompositions returns the indexes of the compositions of comps that
+// implement compositeType and match selector.
+func selectCompositions(comps []packageComposition, compositeType schema.GroupKind, selector labels.Selector) []int {
+	selected := []int{}
+	for i, c := range comps {
+		if c.CompositeType == compositeType && selector.Matches(labels.Set(c.Composition.GetLabels())) {
+			selected = append(selected, i)
+		}
+	}
+	return selected
+}
+
+// findComposition returns the index of the composition of comps with name or
+// -1 if there is none.
+func findComposition(comps []packageComposition, name string) int {
+	for i, c := range comps {
+		if c.Composition.GetName() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// nestedComposite is a composed resource that is a composite resource of
+// the package.
+type nestedComposite struct {
+	lint.Location
+
+	// To is the index of a composition that may be selected for the
+	// composed resource.
+	To int
+
+	// Definite is true if To is the only composition that may be selected.
+	Definite bool
+}
+
+// CheckCompositionNestedComposites checks composed resources that are
+// composite resources or claims of the package. Claims cannot be composed,
+// compositionRef and compositionSelector must select a composition of the
+// package and compositions must not compose each other in a cycle.
+func CheckCompositionNestedComposites(ctx lint.LinterContext, pkg *xpkg.Package) {
+	xrds := getPackageXRDs(pkg)
+	comps := getPackageCompositions(pkg)
+	nested := make([][]nestedComposite, len(comps))
+	for ci, c := range comps {
+		for _, t := range getComposedTemplates(c.Entry, c.Composition) {
+			for ri, r := range t.Composition.Spec.Resources {
+				base := &unstructured.Unstructured{}
+				if err := yaml.Unmarshal(r.Base.Raw, base); err != nil {
+					continue
+				}
+				path := jsonpath.NewJSONPath(t.Path, "resources", ri, "base")
+				compositeType, xrd := nestedCompositeType(ctx, c.Entry, path, base, xrds)
+				if xrd == nil {
+					continue
+				}
+				resolved := resolveNestedCompositions(ctx, c.Entry, path, base, compositeType, xrd, comps)
+				for _, to := range resolved {
+					nested[ci] = append(nested[ci], nestedComposite{
+						Location: lint.Location{Entry: c.Entry, Path: path},
+						To:       to,
+						Definite: len(resolved) == 1,
+					})
+				}
+			}
+		}
+	}
+	reportCompositionCycles(ctx, comps, nested)
+}
+
+// nestedCompositeType returns the composite type and XRD of base or nil if
+// base is not a composite resource or claim of the package. Composed claims
+// are reported.
+func nestedCompositeType(ctx lint.LinterContext, e *xpkg.PackageEntry, path jsonpath.JSONPath, base *unstructured.Unstructured, xrds packageXRDs) (schema.GroupKind, *xpv1.CompositeResourceDefinition) {
+	gk := base.GroupVersionKind().GroupKind()
+	if xrd, exists := xrds.composites[gk]; exists {
+		return gk, xrd
+	}
+	xrd, exists := xrds.claims[gk]
+	if !exists {
+		return gk, nil
+	}
+	kindPath := jsonpath.NewJSONPath(path, "kind")
+	ctx.ReportIssue(lint.Issue{
+		Entry:       e,
+		Path:        kindPath,
+		PathValue:   gk.Kind,
+		Description: fmt.Sprintf(errFmtComposedClaim, gk.Kind, xrd.GetName(), xrd.Spec.Names.Kind),
+		Fixes: []lint.Fix{{
+			Type:        lint.FixTypeSetValue,
+			Description: fmt.Sprintf(errFmtFixComposeComposite, xrd.Spec.Names.Kind),
+			Path:        kindPath,
+			Value:       xrd.Spec.Names.Kind,
+		}},
+	})
+	return schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.Names.Kind}, xrd
+}
+
+// resolveNestedCompositions returns the indexes of the compositions that may
+// be selected for the composite resource base the same way Crossplane does:
+// the enforced composition of the XRD, compositionRef, compositionSelector,
+// the default composition of the XRD or else any composition of the
+// composite type. References that select no composition are reported.
+func resolveNestedCompositions(ctx lint.LinterContext, e *xpkg.PackageEntry, path jsonpath.JSONPath, base *unstructured.Unstructured, compositeType schema.GroupKind, xrd *xpv1.CompositeResourceDefinition, comps []packageComposition) []int {
+	if ref := xrd.Spec.EnforcedCompositionRef; ref != nil {
+		if i := findComposition(comps, ref.Name); i >= 0 {
+			return []int{i}
+		}
+		return nil
+	}
+	if name, _, _ := unstructured.NestedString(base.Object, "spec", "compositionRef", "name"); name != "" {
+		if i := checkCompositionRef(ctx, e, jsonpath.NewJSONPath(path, "spec", "compositionRef", "name"), name, compositeType, comps); i >= 0 {
+			return []int{i}
+		}
+		return nil
+	}
+	if matchLabels, found, _ := unstructured.NestedStringMap(base.Object, "spec", "compositionSelector", "matchLabels"); found {
+		return checkCompositionSelector(ctx, e, jsonpath.NewJSONPath(path, "spec", "compositionSelector"), matchLabels, compositeType, comps)
+	}
+	if ref := xrd.Spec.DefaultCompositionRef; ref != nil {
+		if i := findComposition(comps, ref.Name); i >= 0 {
+			return []int{i}
+		}
+	}
+	return selectCompositions(comps, compositeType, labels.Everything())
+}
+
+// reportCompositionCycles reports a cycle for every group of compositions
+// that compose each other. Cycles whose edges all select a single composition
+// are reported with the default severity, other cycles as warnings because a
+// different composition may be selected.
+func reportCompositionCycles(ctx lint.LinterContext, comps []packageComposition, nested [][]nestedComposite) {
+	definite := make([][]nestedComposite, len(nested))
+	for i, edges := range nested {
+		for _, n := range edges {
+			if n.Definite {
+				definite[i] = append(definite[i], n)
+			}
+		}
+	}
+	inDefiniteCycle := map[int]bool{}
+	for _, component := range stronglyConnectedComponents(definite) {
+		cycle := findCycle(definite, component)
+		if len(cycle) == 0 {
+			continue
+		}
+		for _, n := range component {
+			inDefiniteCycle[n] = true
+		}
+		reportCompositionCycle(ctx, comps, cycle, errFmtCompositionCycle, "")
+	}
+	for _, component := range stronglyConnectedComponents(nested) {
+		reported := false
+		for _, n := range component {
+			reported = reported || inDefiniteCycle[n]
+		}
+		if reported {
+			continue
+		}
+		if cycle := findCycle(nested, component); len(cycle) > 0 {
+			reportCompositionCycle(ctx, comps, cycle, errFmtCompositionMayCycle, lint.SeverityWarning)
+		}
+	}
+}
+
+// reportCompositionCycle reports cycle with severity. The issue points to the
+// composed resource of the first composition of the cycle and lists the
+// composed resources of the other compositions as related.
+func reportCompositionCycle(ctx lint.LinterContext, comps []packageComposition, cycle []nestedComposite, format string, severity lint.Severity) {
+	members := make([]string, 0, len(cycle)+1)
+	related := make([]lint.Location, 0, len(cycle)-1)
+	for i, n := range cycle {
+		c := comps[n.To]
+		members = append(members, fmt.Sprintf(errFmtCompositionCycleMember, c.Composition.GetName(), c.CompositeType.Kind))
+		if i > 0 {
+			related = append(related, n.Location)
+		}
+	}
+	first := comps[cycle[len(cycle)-1].To]
+	members = append([]string{fmt.Sprintf(errFmtCompositionCycleMember, first.Composition.GetName(), first.CompositeType.Kind)}, members...)
+	ctx.ReportIssue(lint.Issue{
+		Entry:       cycle[0].Entry,
+		Path:        cycle[0].Path,
+		Severity:    severity,
+		Related:     related,
+		Description: fmt.Sprintf(format, strings.Join(members, " -> ")),
+	})
+}
+
+// findCycle returns the edges of a cycle through the lowest composition of
+// component. Returns nil if component is a single composition that does not
+// compose itself.
+func findCycle(nested [][]nestedComposite, component []int) []nestedComposite {
+	start := component[0]
+	inComponent := map[int]bool{}
+	for _, n := range component {
+		inComponent[n] = true
+		if n < start {
+			start = n
+		}
+	}
+	visited := map[int]bool{}
+	var visit func(from int, path []nestedComposite) []nestedComposite
+	visit = func(from int, path []nestedComposite) []nestedComposite {
+		visited[from] = true
+		for _, n := range nested[from] {
+			if n.To == start {
+				return append(path, n)
+			}
+			if !inComponent[n.To] || visited[n.To] {
+				continue
+			}
+			if cycle := visit(n.To, append(path, n)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit(start, nil)
+}
+
+// stronglyConnectedComponents returns the strongly connected components of
+// the graph of compositions with the edges nested using Tarjan's algorithm.
+func stronglyConnectedComponents(nested [][]nestedComposite) [][]int {
+	index := make([]int, len(nested))
+	lowlink := make([]int, len(nested))
+	onStack := make([]bool, len(nested))
+	for i := range index {
+		index[i] = -1
+	}
+	stack := []int{}
+	components := [][]int{}
+	next := 0
+
+	var connect func(v int)
+	connect = func(v int) {
+		index[v], lowlink[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, n := range nested[v] {
+			switch {
+			case index[n.To] < 0:
+				connect(n.To)
+				if lowlink[n.To] < lowlink[v] {
+					lowlink[v] = lowlink[n.To]
+				}
+			case onStack[n.To] && index[n.To] < lowlink[v]:
+				lowlink[v] = index[n.To]
+			}
+		}
+		if lowlink[v] != index[v] {
+			return
+		}
+		component := []int{}
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+	for v := range nested {
+		if index[v] < 0 {
+			connect(v)
+		}
+	}
+	return components
+}
@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+// edges builds the nested composites of a graph with the edges from -> to.
+func edges(n int, from ...[2]int) [][]nestedComposite {
+	nested := make([][]nestedComposite, n)
+	for _, e := range from {
+		nested[e[0]] = append(nested[e[0]], nestedComposite{To: e[1], Definite: true})
+	}
+	return nested
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		nested [][]nestedComposite
+		want   [][]int
+	}{
+		"NoEdges": {
+			reason: "Every composition is its own component.",
+			nested: edges(2),
+			want:   [][]int{{0}, {1}},
+		},
+		"SelfLoop": {
+			reason: "A composition composing itself is its own component.",
+			nested: edges(2, [2]int{0, 0}, [2]int{0, 1}),
+			want:   [][]int{{0}, {1}},
+		},
+		"TwoCycle": {
+			reason: "Compositions composing each other form one component.",
+			nested: edges(3, [2]int{0, 1}, [2]int{1, 0}, [2]int{1, 2}),
+			want:   [][]int{{0, 1}, {2}},
+		},
+		"Chain": {
+			reason: "Compositions composing each other without cycle are separate components.",
+			nested: edges(3, [2]int{0, 1}, [2]int{1, 2}),
+			want:   [][]int{{0}, {1}, {2}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := stronglyConnectedComponents(tc.nested)
+			for _, c := range got {
+				sort.Ints(c)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i][0] < got[j][0] })
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nstronglyConnectedComponents(...): want %v, got %v", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		nested    [][]nestedComposite
+		component []int
+		want      []int
+	}{
+		"SelfLoop": {
+			reason:    "A composition composing itself is a cycle.",
+			nested:    edges(1, [2]int{0, 0}),
+			component: []int{0},
+			want:      []int{0},
+		},
+		"TwoCycle": {
+			reason:    "The cycle starts at the lowest composition of the component.",
+			nested:    edges(2, [2]int{0, 1}, [2]int{1, 0}),
+			component: []int{1, 0},
+			want:      []int{1, 0},
+		},
+		"NoCycle": {
+			reason:    "A single composition that does not compose itself is no cycle.",
+			nested:    edges(2, [2]int{0, 1}),
+			component: []int{0},
+			want:      nil,
+		},
+		"CycleThroughComponent": {
+			reason:    "Edges leaving the component are not part of the cycle.",
+			nested:    edges(4, [2]int{0, 3}, [2]int{0, 1}, [2]int{1, 2}, [2]int{2, 0}),
+			component: []int{2, 1, 0},
+			want:      []int{1, 2, 0},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []int
+			for _, n := range findCycle(tc.nested, tc.component) {
+				got = append(got, n.To)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nfindCycle(...): want edges to %v, got %v", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+// testChildXRD defines the composite resource example.org/v1 XChild with the
+// claim Child.
+const testChildXRD = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xchildren.example.org
+spec:
+  group: example.org
+  names:
+    kind: XChild
+    plural: xchildren
+  claimNames:
+    kind: Child
+    plural: children
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+`
+
+// testChildComposition is the composition aws of XChild.
+const testChildComposition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: child
+  labels:
+    provider: aws
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XChild
+  resources: []
+`
+
+func TestCheckCompositionNestedComposites(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		resources string
+		want      []string
+	}{
+		"Selected": {
+			reason: "Composites whose selector matches a composition are valid.",
+			resources: `- name: child
+  base:
+    apiVersion: example.org/v1
+    kind: XChild
+    spec:
+      compositionSelector:
+        matchLabels:
+          provider: aws
+`,
+			want: []string{},
+		},
+		"SelectorMatchesNothing": {
+			reason: "Selectors that match no composition are errors.",
+			resources: `- name: child
+  base:
+    apiVersion: example.org/v1
+    kind: XChild
+    spec:
+      compositionSelector:
+        matchLabels:
+          provider: gcp
+`,
+			want: []string{"error 0.yaml .spec.resources[0].base.spec.compositionSelector: compositionSelector matches no composition of XChild.example.org in package"},
+		},
+		"CompositionNotFound": {
+			reason: "References to missing compositions are errors.",
+			resources: `- name: child
+  base:
+    apiVersion: example.org/v1
+    kind: XChild
+    spec:
+      compositionRef:
+        name: missing
+`,
+			want: []string{"error 0.yaml .spec.resources[0].base.spec.compositionRef.name: composition 'missing' not found in package"},
+		},
+		"ComposedClaim": {
+			reason: "Claims cannot be composed.",
+			resources: `- name: child
+  base:
+    apiVersion: example.org/v1
+    kind: Child
+`,
+			want: []string{" 0.yaml .spec.resources[0].base.kind: claim kind 'Child' of XRD 'xchildren.example.org' cannot be composed, compose the composite kind 'XChild' instead"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := newTestPackage(t, testComposition("", tc.resources), testXRD, testChildXRD, testChildComposition)
+			got := []string{}
+			for _, i := range runRule(t, CheckCompositionNestedComposites, pkg) {
+				got = append(got, string(i.Severity)+" "+formatIssues([]lint.Issue{i})[0])
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nCheckCompositionNestedComposites(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package rules
+
+import (
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+// composedTemplates are resource templates and patch sets of a composition.
+// They are either part of the composition itself or of the input of a
+// function-patch-and-transform pipeline step.
+type composedTemplates struct {
+	// Composition that holds the resources and patch sets.
+	Composition *xpv1.Composition
+
+	// Path of the object that holds the resources and patchSets fields.
+	Path jsonpath.JSONPath
+}
+
+// getComposedTemplates returns all composedTemplates of comp. Pipeline steps
+// with invalid input are skipped as they are reported by
+// composition.checkFunctions.
+func getComposedTemplates(e *xpkg.PackageEntry, comp *xpv1.Composition) []composedTemplates {
+	all := xpkg.GetComposedTemplates(e, comp)
+	templates := make([]composedTemplates, len(all))
+	for i, t := range all {
+		tComp := comp
+		if i > 0 {
+			tComp = comp.DeepCopy()
+			tComp.Spec.Resources = t.Resources
+			tComp.Spec.PatchSets = t.PatchSets
+		}
+		templates[i] = composedTemplates{Composition: tComp, Path: t.Path}
+	}
+	return templates
+}
+
+// resolvedPatch is a patch of a composed resource with patch sets inlined.
+type resolvedPatch struct {
+	xpv1.Patch
+
+	// Path of the patch within the composition.
+	Path jsonpath.JSONPath
+
+	// UsedBy is the path of the PatchSet patch of the resource that inlined
+	// the patch. Nil if the patch is not part of a patch set.
+	UsedBy jsonpath.JSONPath
+}
+
+// resolveResourcePatches returns the patches of the resource at index in t
+// with all patch sets inlined. Patches of a patch set point to their
+// definition in patchSets. Unresolvable patch sets are skipped as they are
+// reported by composition.checkPathFieldPaths.
+func resolveResourcePatches(t composedTemplates, index int) []resolvedPatch {
+	comp := t.Composition
+	patches := []resolvedPatch{}
+	for ip, p := range comp.Spec.Resources[index].Patches {
+		if p.Type != xpv1.PatchTypePatchSet {
+			patches = append(patches, resolvedPatch{
+				Patch: p,
+				Path:  jsonpath.NewJSONPath(t.Path, "resources", index, "patches", ip),
+			})
+			continue
+		}
+		if p.PatchSetName == nil {
+			continue
+		}
+		for is, s := range comp.Spec.PatchSets {
+			if s.Name != *p.PatchSetName {
+				continue
+			}
+			for isp, sp := range s.Patches {
+				patches = append(patches, resolvedPatch{
+					Patch:  sp,
+					Path:   jsonpath.NewJSONPath(t.Path, "patchSets", is, "patches", isp),
+					UsedBy: jsonpath.NewJSONPath(t.Path, "resources", index, "patches", ip),
+				})
+			}
+			break
+		}
+	}
+	return patches
+}
@@ -0,0 +1,212 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtInvalidFromFieldPathPolicy = "invalid fromFieldPath policy, expected '%s' or '%s'"
+	errFmtOptionalPatchRequiredField = "optional patch reads '%s' which is optional and has no default but writes required field '%s'"
+	errFmtMergeOptionTargetType      = "mergeOptions.%s requires a target of type '%s' but '%s' is of type '%s'"
+)
+
+// CheckCompositionPatchPolicies checks the policies of all composition
+// patches.
+func CheckCompositionPatchPolicies(ctx lint.LinterContext, pkg *xpkg.Package) {
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		compositeGv, err := schema.ParseGroupVersion(comp.Spec.CompositeTypeRef.APIVersion)
+		if err != nil {
+			continue
+		}
+		compositeCRD := ctx.GetCRDSchema(compositeGv.WithKind(comp.Spec.CompositeTypeRef.Kind))
+		if compositeCRD == nil {
+			// Reported by composition.checkPathFieldPaths.
+			continue
+		}
+		for _, t := range getComposedTemplates(e, comp) {
+			for ri, r := range t.Composition.Spec.Resources {
+				base := &unstructured.Unstructured{}
+				if err := yaml.Unmarshal(r.Base.Raw, base); err != nil {
+					continue
+				}
+				baseCRD := ctx.GetCRDSchema(base.GroupVersionKind())
+				if baseCRD == nil {
+					continue
+				}
+				baseSchema := composedResourceSchema(ctx, base, baseCRD.Schema.OpenAPIV3Schema)
+				for _, p := range resolveResourcePatches(t, ri) {
+					sctx := scopedContext{
+						linterContext: ctx,
+						entry:         e,
+						basePath:      p.Path,
+					}
+					if p.UsedBy != nil {
+						sctx.related = []lint.Location{{Entry: e, Path: p.UsedBy}}
+					}
+					checkPatchPolicy(sctx, p.Patch, base, compositeCRD.Schema.OpenAPIV3Schema, baseSchema)
+				}
+			}
+		}
+	}
+}
+
+func checkPatchPolicy(ctx scopedContext, p xpv1.Patch, base *unstructured.Unstructured, compositeSchema, baseSchema *extv1.JSONSchemaProps) {
+	if p.Policy != nil && p.Policy.FromFieldPath != nil {
+		switch *p.Policy.FromFieldPath {
+		case xpv1.FromFieldPathPolicyOptional, xpv1.FromFieldPathPolicyRequired:
+		default:
+			ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("policy", "fromFieldPath"),
+				fmt.Sprintf(errFmtInvalidFromFieldPathPolicy, xpv1.FromFieldPathPolicyOptional, xpv1.FromFieldPathPolicyRequired),
+				string(*p.Policy.FromFieldPath))
+		}
+	}
+
+	toFieldPath := p.ToFieldPath
+	if toFieldPath == nil {
+		toFieldPath = p.FromFieldPath
+	}
+	switch p.Type {
+	case "", xpv1.PatchTypeFromCompositeFieldPath:
+		if isOptionalPatch(p) {
+			checkOptionalPatchTarget(ctx, p.FromFieldPath, toFieldPath, base, compositeSchema, baseSchema)
+		}
+		checkMergeOptions(ctx, p, toFieldPath, baseSchema)
+	case xpv1.PatchTypeCombineFromComposite:
+		checkMergeOptions(ctx, p, p.ToFieldPath, baseSchema)
+	case xpv1.PatchTypeToCompositeFieldPath:
+		checkMergeOptions(ctx, p, toFieldPath, compositeSchema)
+	case xpv1.PatchTypeCombineToComposite:
+		checkMergeOptions(ctx, p, p.ToFieldPath, compositeSchema)
+	}
+}
+
+// isOptionalPatch determines if p is a no-op if its fromFieldPath does not
+// exist. This is the default behaviour of Crossplane.
+func isOptionalPatch(p xpv1.Patch) bool {
+	return p.Policy == nil || p.Policy.FromFieldPath == nil || *p.Policy.FromFieldPath == xpv1.FromFieldPathPolicyOptional
+}
+
+// checkOptionalPatchTarget reports an issue if an optional patch reads a field
+// that may not be set and writes it to a required field that is not set by the
+// base.
+func checkOptionalPatchTarget(ctx scopedContext, fromFieldPath, toFieldPath *string, base *unstructured.Unstructured, fromSchema, toSchema *extv1.JSONSchemaProps) {
+	if fromFieldPath == nil || toFieldPath == nil {
+		return
+	}
+	fromSegments, fromSchemas, ok := resolveFullFieldPath(fromSchema, *fromFieldPath)
+	if !ok || !isOptionalWithoutDefault(fromSegments, fromSchemas) {
+		return
+	}
+	toSegments, toSchemas, ok := resolveFullFieldPath(toSchema, *toFieldPath)
+	if !ok || !isRequiredField(toSegments, toSchemas) {
+		return
+	}
+	if _, err := fieldpath.Pave(base.Object).GetValue(*toFieldPath); err == nil {
+		// The base provides a value if the patch is skipped.
+		return
+	}
+	ctx.ReportIssue(lint.Issue{
+		Severity:    lint.SeverityWarning,
+		Entry:       ctx.entry,
+		Path:        jsonpath.NewJSONPath(ctx.basePath, "fromFieldPath"),
+		PathValue:   *fromFieldPath,
+		Description: fmt.Sprintf(errFmtOptionalPatchRequiredField, *fromFieldPath, *toFieldPath),
+	})
+}
+
+// checkMergeOptions reports an issue if the merge options of p do not match the
+// type of the target field.
+func checkMergeOptions(ctx scopedContext, p xpv1.Patch, toFieldPath *string, toSchema *extv1.JSONSchemaProps) {
+	if p.Policy == nil || p.Policy.MergeOptions == nil || toFieldPath == nil {
+		return
+	}
+	_, schemas, ok := resolveFullFieldPath(toSchema, *toFieldPath)
+	if !ok {
+		return
+	}
+	targetType := schemas[len(schemas)-1].Type
+	if targetType == "" {
+		targetType = "object"
+	}
+	mo := p.Policy.MergeOptions
+	if pointer.BoolDeref(mo.AppendSlice, false) && targetType != "array" {
+		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("policy", "mergeOptions", "appendSlice"),
+			fmt.Sprintf(errFmtMergeOptionTargetType, "appendSlice", "array", *toFieldPath, targetType),
+			"true")
+	}
+	if pointer.BoolDeref(mo.KeepMapValues, false) && targetType != "object" {
+		ctx.ReportIssueFieldPath(jsonpath.NewJSONPath("policy", "mergeOptions", "keepMapValues"),
+			fmt.Sprintf(errFmtMergeOptionTargetType, "keepMapValues", "object", *toFieldPath, targetType),
+			"true")
+	}
+}
+
+// resolveFullFieldPath resolves the schemas of all segments of rawPath. Returns
+// false if the path is invalid or points into a field with unknown schema.
+func resolveFullFieldPath(root *extv1.JSONSchemaProps, rawPath string) (fieldpath.Segments, []*extv1.JSONSchemaProps, bool) {
+	segments, err := fieldpath.Parse(rawPath)
+	if err != nil || len(segments) == 0 {
+		return nil, nil, false
+	}
+	schemas, err := resolveFieldPathSchemas(root, segments)
+	if err != nil || len(schemas) != len(segments)+1 {
+		return nil, nil, false
+	}
+	return segments, schemas, true
+}
+
+// isOptionalWithoutDefault determines if the field the segments point to may
+// be missing, i.e. if it or one of its parents is neither required by its
+// parent nor has a default.
+func isOptionalWithoutDefault(segments fieldpath.Segments, schemas []*extv1.JSONSchemaProps) bool {
+	for i, s := range segments {
+		if s.Type != fieldpath.SegmentField {
+			continue
+		}
+		if !containsString(schemas[i].Required, s.Field) && schemas[i+1].Default == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isRequiredField determines if the field the segments point to must be set,
+// i.e. if it and all its parents are required by their parents.
+func isRequiredField(segments fieldpath.Segments, schemas []*extv1.JSONSchemaProps) bool {
+	for i, s := range segments {
+		if s.Type != fieldpath.SegmentField || !containsString(schemas[i].Required, s.Field) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
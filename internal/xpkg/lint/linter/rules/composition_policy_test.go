@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckCompositionPatchPolicies(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		resources string
+		want      []string
+	}{
+		"RequiredSource": {
+			reason: "Patches reading required fields always write their target.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.name
+    toFieldPath: spec.forProvider.region
+`,
+			want: []string{},
+		},
+		"OptionalSource": {
+			reason: "Optional patches reading optional fields may skip required targets.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.parameters
+    toFieldPath: spec.forProvider.region
+`,
+			want: []string{"0.yaml .spec.resources[0].patches[0].fromFieldPath: optional patch reads 'spec.parameters' which is optional and has no default but writes required field 'spec.forProvider.region'"},
+		},
+		"RequiredFieldOfOptionalParent": {
+			reason: "Required fields of optional parents without default may be missing.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.parameters.region
+    toFieldPath: spec.forProvider.region
+`,
+			want: []string{"0.yaml .spec.resources[0].patches[0].fromFieldPath: optional patch reads 'spec.parameters.region' which is optional and has no default but writes required field 'spec.forProvider.region'"},
+		},
+		"DefaultedSource": {
+			reason: "Fields with default are always set.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.size
+    toFieldPath: spec.forProvider.region
+`,
+			want: []string{},
+		},
+		"RequiredPolicy": {
+			reason: "Patches with the Required policy fail instead of skipping their target.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.parameters.region
+    toFieldPath: spec.forProvider.region
+    policy:
+      fromFieldPath: Required
+`,
+			want: []string{},
+		},
+		"TargetSetByBase": {
+			reason: "Targets set by the base keep their value if the patch is skipped.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+    spec:
+      forProvider:
+        region: us-east-1
+  patches:
+  - fromFieldPath: spec.parameters.region
+    toFieldPath: spec.forProvider.region
+`,
+			want: []string{},
+		},
+		"OptionalTarget": {
+			reason: "Optional targets may be skipped.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.parameters.region
+    toFieldPath: spec.forProvider.tags.region
+`,
+			want: []string{},
+		},
+		"InvalidPolicy": {
+			reason: "Unknown fromFieldPath policies are reported.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.name
+    toFieldPath: spec.forProvider.region
+    policy:
+      fromFieldPath: Always
+`,
+			want: []string{"0.yaml .spec.resources[0].patches[0].policy.fromFieldPath: invalid fromFieldPath policy, expected 'Optional' or 'Required'"},
+		},
+		"MergeOptions": {
+			reason: "Merge options that do not match the type of the target are reported.",
+			resources: `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.name
+    toFieldPath: spec.forProvider.region
+    policy:
+      mergeOptions:
+        appendSlice: true
+  - fromFieldPath: spec.name
+    toFieldPath: spec.forProvider.grants
+    policy:
+      mergeOptions:
+        appendSlice: true
+        keepMapValues: true
+`,
+			want: []string{
+				"0.yaml .spec.resources[0].patches[0].policy.mergeOptions.appendSlice: mergeOptions.appendSlice requires a target of type 'array' but 'spec.forProvider.region' is of type 'string'",
+				"0.yaml .spec.resources[0].patches[1].policy.mergeOptions.keepMapValues: mergeOptions.keepMapValues requires a target of type 'object' but 'spec.forProvider.grants' is of type 'array'",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := newTestPackage(t, testComposition("", tc.resources), testXRD, testCRD)
+			got := formatIssues(runRule(t, CheckCompositionPatchPolicies, pkg))
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nCheckCompositionPatchPolicies(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtSameLabels             = "composition has the same labels as composition '%s' of %s, compositionSelector cannot tell them apart"
+	errFmtLabelsOfOther          = "labels of composition are also carried by composition '%s' of %s, compositionSelector cannot select it alone"
+	errFmtNoDefaultComposition   = "%d compositions implement %s but neither defaultCompositionRef nor enforcedCompositionRef is set, resources without compositionRef or compositionSelector get any of them"
+	errFmtSelectorMatchesSeveral = "compositionSelector matches %d compositions of %s: %s, one of them is chosen at random"
+	errFmtSelectorNoComposition  = "compositionSelector matches no composition of %s in package"
+)
+
+// CheckCompositionSelection checks if the compositions of a composite type can
+// be selected unambiguously: compositions of the same type need distinct
+// labels, XRDs with several compositions need a default composition and the
+// composition references of composite resources and claims in the package,
+// such as examples, must select exactly one composition.
+func CheckCompositionSelection(ctx lint.LinterContext, pkg *xpkg.Package) {
+	comps := getPackageCompositions(pkg)
+	checkCompositionLabels(ctx, comps)
+
+	xrds := getPackageXRDs(pkg)
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsXRD() {
+			continue
+		}
+		xrd, err := e.AsXRD()
+		if err != nil {
+			continue
+		}
+		checkXRDCompositionRefs(ctx, e, xrd, comps)
+	}
+
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		gk := e.Object.GroupVersionKind().GroupKind()
+		compositeType := gk
+		if xrd, exists := xrds.claims[gk]; exists {
+			compositeType = schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.Names.Kind}
+		} else if _, exists := xrds.composites[gk]; !exists {
+			continue
+		}
+		checkCompositeSelection(ctx, e, compositeType, comps)
+	}
+}
+
+// checkCompositionLabels reports compositions whose labels are a subset of the
+// labels of another composition of the same type. Compositions with identical
+// labels are reported once, for the later of comps, which are sorted by
+// getPackageCompositions.
+func checkCompositionLabels(ctx lint.LinterContext, comps []packageComposition) {
+	for i, c := range comps {
+		own := c.Composition.GetLabels()
+		if len(own) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(own)
+		for j, other := range comps {
+			if i == j || other.CompositeType != c.CompositeType || !selector.Matches(labels.Set(other.Composition.GetLabels())) {
+				continue
+			}
+			description := errFmtLabelsOfOther
+			if len(other.Composition.GetLabels()) == len(own) {
+				if j > i {
+					// Reported for the later composition.
+					continue
+				}
+				description = errFmtSameLabels
+			}
+			ctx.ReportIssue(lint.Issue{
+				Entry:       c.Entry,
+				Path:        jsonpath.NewJSONPath("metadata", "labels"),
+				Related:     []lint.Location{{Entry: other.Entry, Path: jsonpath.NewJSONPath("metadata", "labels")}},
+				Description: fmt.Sprintf(description, other.Composition.GetName(), c.CompositeType),
+			})
+			break
+		}
+	}
+}
+
+// checkXRDCompositionRefs checks if the default and enforced compositions of
+// xrd exist and if a default is set when several compositions implement xrd.
+func checkXRDCompositionRefs(ctx lint.LinterContext, e *xpkg.PackageEntry, xrd *xpv1.CompositeResourceDefinition, comps []packageComposition) {
+	compositeType := schema.GroupKind{Group: xrd.Spec.Group, Kind: xrd.Spec.Names.Kind}
+	if ref := xrd.Spec.DefaultCompositionRef; ref != nil {
+		checkCompositionRef(ctx, e, jsonpath.NewJSONPath("spec", "defaultCompositionRef", "name"), ref.Name, compositeType, comps)
+	}
+	if ref := xrd.Spec.EnforcedCompositionRef; ref != nil {
+		checkCompositionRef(ctx, e, jsonpath.NewJSONPath("spec", "enforcedCompositionRef", "name"), ref.Name, compositeType, comps)
+	}
+
+	implementing := selectCompositions(comps, compositeType, labels.Everything())
+	if len(implementing) > 1 && xrd.Spec.DefaultCompositionRef == nil && xrd.Spec.EnforcedCompositionRef == nil {
+		related := make([]lint.Location, 0, len(implementing))
+		for _, i := range implementing {
+			related = append(related, lint.Location{Entry: comps[i].Entry, Path: jsonpath.NewJSONPath("spec", "compositeTypeRef")})
+		}
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        jsonpath.NewJSONPath("spec"),
+			Related:     related,
+			Description: fmt.Sprintf(errFmtNoDefaultComposition, len(implementing), compositeType),
+		})
+	}
+}
+
+// checkCompositeSelection checks if the compositionRef or compositionSelector
+// of the composite resource or claim e selects exactly one composition of
+// compositeType.
+func checkCompositeSelection(ctx lint.LinterContext, e *xpkg.PackageEntry, compositeType schema.GroupKind, comps []packageComposition) {
+	if name, _, _ := unstructured.NestedString(e.Object.Object, "spec", "compositionRef", "name"); name != "" {
+		checkCompositionRef(ctx, e, jsonpath.NewJSONPath("spec", "compositionRef", "name"), name, compositeType, comps)
+		return
+	}
+	matchLabels, found, _ := unstructured.NestedStringMap(e.Object.Object, "spec", "compositionSelector", "matchLabels")
+	if !found {
+		return
+	}
+	path := jsonpath.NewJSONPath("spec", "compositionSelector")
+	selected := checkCompositionSelector(ctx, e, path, matchLabels, compositeType, comps)
+	if len(selected) > 1 {
+		names := make([]string, len(selected))
+		related := make([]lint.Location, len(selected))
+		for i, s := range selected {
+			names[i] = comps[s].Composition.GetName()
+			related[i] = lint.Location{Entry: comps[s].Entry, Path: jsonpath.NewJSONPath("metadata", "labels")}
+		}
+		sort.Strings(names)
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        path,
+			Related:     related,
+			Description: fmt.Sprintf(errFmtSelectorMatchesSeveral, len(selected), compositeType, strings.Join(names, ", ")),
+		})
+	}
+}
+
+// checkCompositionSelector reports if the compositionSelector at path selects
+// no composition of compositeType. Returns the indexes of the selected
+// compositions.
+func checkCompositionSelector(ctx lint.LinterContext, e *xpkg.PackageEntry, path jsonpath.JSONPath, matchLabels map[string]string, compositeType schema.GroupKind, comps []packageComposition) []int {
+	selected := selectCompositions(comps, compositeType, labels.SelectorFromSet(matchLabels))
+	if len(selected) == 0 {
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        path,
+			Severity:    lint.SeverityError,
+			Description: fmt.Sprintf(errFmtSelectorNoComposition, compositeType),
+		})
+	}
+	return selected
+}
+
+// checkCompositionRef reports if the composition name at path does not exist
+// or does not implement compositeType. Returns the index of the composition
+// in comps or -1 if it was reported.
+func checkCompositionRef(ctx lint.LinterContext, e *xpkg.PackageEntry, path jsonpath.JSONPath, name string, compositeType schema.GroupKind, comps []packageComposition) int {
+	i := findComposition(comps, name)
+	switch {
+	case i < 0:
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        path,
+			PathValue:   name,
+			Severity:    lint.SeverityError,
+			Description: fmt.Sprintf(errFmtCompositionNotFound, name),
+		})
+		return -1
+	case comps[i].CompositeType != compositeType:
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        path,
+			PathValue:   name,
+			Severity:    lint.SeverityError,
+			Description: fmt.Sprintf(errFmtCompositionWrongType, name, comps[i].CompositeType, compositeType),
+		})
+		return -1
+	}
+	return i
+}
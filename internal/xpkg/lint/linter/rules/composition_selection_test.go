@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+// childComposition returns a composition name of XChild with labels.
+func childComposition(name, labels string) string {
+	return `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: ` + name + `
+  labels:
+` + indent(labels, "    ") + `spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XChild
+  resources: []
+`
+}
+
+// childClaim returns a Child claim with spec.
+func childClaim(spec string) string {
+	return `apiVersion: example.org/v1
+kind: Child
+metadata:
+  name: example
+spec:
+` + indent(spec, "  ")
+}
+
+func TestCheckCompositionSelection(t *testing.T) {
+	xrdWithDefault := testChildXRD + `  defaultCompositionRef:
+    name: missing
+`
+	cases := map[string]struct {
+		reason    string
+		manifests []string
+		want      []string
+	}{
+		"DistinctLabels": {
+			reason:    "Compositions with distinct labels and a default composition can be selected.",
+			manifests: []string{testChildXRD + "  defaultCompositionRef:\n    name: aws\n", childComposition("aws", "provider: aws"), childComposition("gcp", "provider: gcp")},
+			want:      []string{},
+		},
+		"SameLabels": {
+			reason:    "Compositions with the same labels are reported once, for the later composition.",
+			manifests: []string{testChildXRD + "  defaultCompositionRef:\n    name: a\n", childComposition("a", "provider: aws"), childComposition("b", "provider: aws")},
+			want:      []string{"2.yaml .metadata.labels: composition has the same labels as composition 'a' of XChild.example.org, compositionSelector cannot tell them apart"},
+		},
+		"LabelsOfOther": {
+			reason:    "Compositions whose labels are carried by another composition cannot be selected alone.",
+			manifests: []string{testChildXRD + "  defaultCompositionRef:\n    name: a\n", childComposition("a", "provider: aws"), childComposition("b", "provider: aws\nregion: eu")},
+			want:      []string{"1.yaml .metadata.labels: labels of composition are also carried by composition 'b' of XChild.example.org, compositionSelector cannot select it alone"},
+		},
+		"NoDefaultComposition": {
+			reason:    "XRDs with several compositions need a default composition.",
+			manifests: []string{testChildXRD, childComposition("aws", "provider: aws"), childComposition("gcp", "provider: gcp")},
+			want:      []string{"0.yaml .spec: 2 compositions implement XChild.example.org but neither defaultCompositionRef nor enforcedCompositionRef is set, resources without compositionRef or compositionSelector get any of them"},
+		},
+		"DefaultNotFound": {
+			reason:    "Default compositions must exist.",
+			manifests: []string{xrdWithDefault, childComposition("aws", "provider: aws")},
+			want:      []string{"0.yaml .spec.defaultCompositionRef.name: composition 'missing' not found in package"},
+		},
+		"ClaimSelectorMatchesSeveral": {
+			reason: "Selectors of claims must match a single composition.",
+			manifests: []string{
+				testChildXRD + "  defaultCompositionRef:\n    name: a\n",
+				childComposition("a", "provider: aws\nregion: eu"),
+				childComposition("b", "provider: aws\nregion: us"),
+				childClaim("compositionSelector:\n  matchLabels:\n    provider: aws\n"),
+			},
+			want: []string{"3.yaml .spec.compositionSelector: compositionSelector matches 2 compositions of XChild.example.org: a, b, one of them is chosen at random"},
+		},
+		"ClaimSelectorMatchesNothing": {
+			reason:    "Selectors of claims must match a composition.",
+			manifests: []string{testChildXRD, childComposition("a", "provider: aws"), childClaim("compositionSelector:\n  matchLabels:\n    provider: gcp\n")},
+			want:      []string{"2.yaml .spec.compositionSelector: compositionSelector matches no composition of XChild.example.org in package"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := formatIssues(runRule(t, CheckCompositionSelection, newTestPackage(t, tc.manifests...)))
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nCheckCompositionSelection(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
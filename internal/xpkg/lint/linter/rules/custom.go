@@ -0,0 +1,203 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/config"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	celVarObject      = "object"
+	celVarResource    = "resource"
+	celVarComposition = "composition"
+
+	errCustomRuleName          = "custom rule has no name"
+	errFmtCustomRuleTarget     = "unknown target '%s', expected '%s' or '%s'"
+	errCustomRuleExpression    = "custom rule has no expression"
+	errCreateCELEnvironment    = "failed to create CEL environment"
+	errCompileCELExpression    = "failed to compile CEL expression"
+	errCustomRulePath          = "invalid path"
+	errFmtEvaluateCustomRule   = "failed to evaluate custom rule: %s"
+	errFmtCustomRuleNotBool    = "custom rule evaluated to %s instead of bool"
+	errFmtCustomRuleViolated   = "custom rule '%s' violated: %s"
+	errConvertComposedTemplate = "failed to convert composed template"
+)
+
+// CustomRule reports an issue for each object of a package that does not
+// satisfy a CEL expression.
+type CustomRule struct {
+	rule     config.CustomRule
+	severity lint.Severity
+	program  cel.Program
+	path     jsonpath.JSONPath
+}
+
+// NewCustomRule compiles the CEL expression of rule.
+func NewCustomRule(rule config.CustomRule) (*CustomRule, error) {
+	if rule.Name == "" {
+		return nil, errors.New(errCustomRuleName)
+	}
+	switch rule.Match.Target {
+	case "", config.CustomRuleTargetObject, config.CustomRuleTargetComposedBase:
+	default:
+		return nil, errors.Errorf(errFmtCustomRuleTarget, rule.Match.Target, config.CustomRuleTargetObject, config.CustomRuleTargetComposedBase)
+	}
+	severity := lint.SeverityError
+	if rule.Severity != "" {
+		var err error
+		if severity, err = lint.ParseSeverity(rule.Severity); err != nil {
+			return nil, err
+		}
+	}
+	if rule.Expression == "" {
+		return nil, errors.New(errCustomRuleExpression)
+	}
+
+	mapType := decls.NewMapType(decls.String, decls.Dyn)
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar(celVarObject, mapType),
+		decls.NewVar(celVarResource, mapType),
+		decls.NewVar(celVarComposition, mapType),
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateCELEnvironment)
+	}
+	ast, issues := env.Compile(rule.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, errors.Wrap(issues.Err(), errCompileCELExpression)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, errors.Wrap(err, errCompileCELExpression)
+	}
+
+	var path jsonpath.JSONPath
+	if rule.Path != "" {
+		path, err = jsonpath.Parse(rule.Path)
+		if err != nil {
+			return nil, errors.Wrap(err, errCustomRulePath)
+		}
+	}
+	return &CustomRule{rule: rule, severity: severity, program: program, path: path}, nil
+}
+
+// Check all objects of pkg matched by the rule.
+func (r *CustomRule) Check(ctx lint.LinterContext, pkg *xpkg.Package) {
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if r.rule.Match.Target == config.CustomRuleTargetComposedBase {
+			r.checkComposedBases(ctx, e)
+			continue
+		}
+		if !r.matches(e.Object.GetAPIVersion(), e.Object.GetKind()) {
+			continue
+		}
+		r.evaluate(ctx, e, nil, e.Object.Object, map[string]any{
+			celVarObject:      e.Object.Object,
+			celVarResource:    map[string]any{},
+			celVarComposition: map[string]any{},
+		})
+	}
+}
+
+func (r *CustomRule) checkComposedBases(ctx lint.LinterContext, e *xpkg.PackageEntry) {
+	if !e.IsComposition() {
+		return
+	}
+	comp, err := e.AsComposition()
+	if err != nil {
+		// Reported by composition.checkCompositeType.
+		return
+	}
+	for _, t := range getComposedTemplates(e, comp) {
+		for ir, res := range t.Composition.Spec.Resources {
+			base := &unstructured.Unstructured{}
+			if err := base.UnmarshalJSON(res.Base.Raw); err != nil {
+				continue
+			}
+			if !r.matches(base.GetAPIVersion(), base.GetKind()) {
+				continue
+			}
+			basePath := jsonpath.NewJSONPath(t.Path, "resources", ir, "base")
+			resource, err := toMap(res)
+			if err != nil {
+				ctx.ReportIssue(lint.Issue{
+					Entry:       e,
+					Path:        basePath,
+					Description: errors.Wrap(err, errConvertComposedTemplate).Error(),
+				})
+				continue
+			}
+			r.evaluate(ctx, e, basePath, base.Object, map[string]any{
+				celVarObject:      base.Object,
+				celVarResource:    resource,
+				celVarComposition: e.Object.Object,
+			})
+		}
+	}
+}
+
+func (r *CustomRule) matches(apiVersion, kind string) bool {
+	return (r.rule.Match.APIVersion == "" || r.rule.Match.APIVersion == apiVersion) &&
+		(r.rule.Match.Kind == "" || r.rule.Match.Kind == kind)
+}
+
+// evaluate the rule with vars and report an issue for e if it is violated.
+// Issues point to r.path within object which is located at objectPath.
+func (r *CustomRule) evaluate(ctx lint.LinterContext, e *xpkg.PackageEntry, objectPath jsonpath.JSONPath, object map[string]any, vars map[string]any) {
+	// Issues for missing fields point to the object itself.
+	issue := lint.Issue{
+		Severity: r.severity,
+		Entry:    e,
+		Path:     objectPath,
+	}
+	if len(r.path) > 0 {
+		if value, err := fieldpath.Pave(object).GetValue(r.rule.Path); err == nil {
+			issue.Path = append(append(jsonpath.JSONPath{}, objectPath...), r.path...)
+			issue.PathValue = fmt.Sprint(value)
+		}
+	}
+
+	val, _, err := r.program.Eval(vars)
+	if err != nil {
+		issue.Description = fmt.Sprintf(errFmtEvaluateCustomRule, err)
+		ctx.ReportIssue(issue)
+		return
+	}
+	ok, isBool := val.Value().(bool)
+	switch {
+	case !isBool:
+		issue.Description = fmt.Sprintf(errFmtCustomRuleNotBool, val.Type().TypeName())
+	case ok:
+		return
+	case r.rule.Message != "":
+		issue.Description = r.rule.Message
+	default:
+		issue.Description = fmt.Sprintf(errFmtCustomRuleViolated, r.rule.Name, r.rule.Expression)
+	}
+	ctx.ReportIssue(issue)
+}
+
+// toMap converts obj to its unstructured representation.
+func toMap(obj any) (map[string]any, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]any{}
+	if err := utiljson.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,172 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/config"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+const customRuleComposition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XExample
+  resources:
+  - name: bucket
+    base:
+      apiVersion: s3.aws.upbound.io/v1beta1
+      kind: Bucket
+      spec:
+        deletionPolicy: Delete
+  - name: queue
+    base:
+      apiVersion: sqs.aws.upbound.io/v1beta1
+      kind: Queue
+      spec:
+        deletionPolicy: Orphan
+`
+
+const customRuleConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: example
+data:
+  key: value
+`
+
+func TestCustomRule(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		rule   config.CustomRule
+		want   []string
+	}{
+		"ObjectSatisfied": {
+			reason: "No issue is reported for objects that satisfy the expression.",
+			rule: config.CustomRule{
+				Name:       "labels",
+				Match:      config.CustomRuleMatch{Kind: "ConfigMap"},
+				Expression: `object.data.key == "value"`,
+			},
+			want: []string{},
+		},
+		"ObjectViolated": {
+			reason: "Objects matched by kind that violate the expression are reported with the expression.",
+			rule: config.CustomRule{
+				Name:       "data",
+				Match:      config.CustomRuleMatch{Kind: "ConfigMap"},
+				Expression: `object.data.key == "other"`,
+			},
+			want: []string{`1.yaml : custom rule 'data' violated: object.data.key == "other"`},
+		},
+		"Message": {
+			reason: "The message of the rule is used as description and the issue points to the path of the rule.",
+			rule: config.CustomRule{
+				Name:       "data",
+				Match:      config.CustomRuleMatch{Kind: "ConfigMap"},
+				Expression: `object.data.key == "other"`,
+				Message:    "key must be other",
+				Path:       "data.key",
+			},
+			want: []string{"1.yaml .data.key: key must be other"},
+		},
+		"NotBool": {
+			reason: "Expressions that do not evaluate to bool are reported.",
+			rule: config.CustomRule{
+				Name:       "data",
+				Match:      config.CustomRuleMatch{Kind: "ConfigMap"},
+				Expression: `object.data.key`,
+			},
+			want: []string{"1.yaml : custom rule evaluated to string instead of bool"},
+		},
+		"ComposedBases": {
+			reason: "Rules for composed bases are evaluated for the matching bases with the resource and the composition.",
+			rule: config.CustomRule{
+				Name:       "deletionPolicy",
+				Match:      config.CustomRuleMatch{Target: config.CustomRuleTargetComposedBase, APIVersion: "s3.aws.upbound.io/v1beta1"},
+				Expression: `object.spec.deletionPolicy == "Orphan" || resource.name != "bucket" || composition.metadata.name != "example"`,
+				Path:       "spec.deletionPolicy",
+			},
+			want: []string{`0.yaml .spec.resources[0].base.spec.deletionPolicy: custom rule 'deletionPolicy' violated: object.spec.deletionPolicy == "Orphan" || resource.name != "bucket" || composition.metadata.name != "example"`},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewCustomRule(tc.rule)
+			if err != nil {
+				t.Fatalf("\n%s\nNewCustomRule(...): unexpected error: %v", tc.reason, err)
+			}
+			got := formatIssues(runRule(t, r.Check, newTestPackage(t, customRuleComposition, customRuleConfigMap)))
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nCheck(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewCustomRule(t *testing.T) {
+	valid := config.CustomRule{Name: "r", Expression: "true"}
+	cases := map[string]struct {
+		reason       string
+		rule         config.CustomRule
+		wantSeverity lint.Severity
+		wantErr      bool
+	}{
+		"Defaults": {
+			reason:       "Issues of custom rules are errors by default.",
+			rule:         valid,
+			wantSeverity: lint.SeverityError,
+		},
+		"Severity": {
+			reason:       "The severity of the rule is used.",
+			rule:         config.CustomRule{Name: "r", Expression: "true", Severity: "info"},
+			wantSeverity: lint.SeverityInfo,
+		},
+		"UnknownSeverity": {
+			reason:  "Unknown severities are rejected.",
+			rule:    config.CustomRule{Name: "r", Expression: "true", Severity: "fatal"},
+			wantErr: true,
+		},
+		"NoName": {
+			reason:  "Rules must have a name.",
+			rule:    config.CustomRule{Expression: "true"},
+			wantErr: true,
+		},
+		"UnknownTarget": {
+			reason:  "Unknown targets are rejected.",
+			rule:    config.CustomRule{Name: "r", Expression: "true", Match: config.CustomRuleMatch{Target: "Other"}},
+			wantErr: true,
+		},
+		"InvalidExpression": {
+			reason:  "Expressions that do not compile are rejected.",
+			rule:    config.CustomRule{Name: "r", Expression: "object.("},
+			wantErr: true,
+		},
+		"InvalidPath": {
+			reason:  "Invalid paths are rejected.",
+			rule:    config.CustomRule{Name: "r", Expression: "true", Path: "spec["},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewCustomRule(tc.rule)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("\n%s\nNewCustomRule(...): want error, got nil", tc.reason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("\n%s\nNewCustomRule(...): unexpected error: %v", tc.reason, err)
+			}
+			if r.severity != tc.wantSeverity {
+				t.Errorf("\n%s\nNewCustomRule(...): want severity %s, got %s", tc.reason, tc.wantSeverity, r.severity)
+			}
+		})
+	}
+}
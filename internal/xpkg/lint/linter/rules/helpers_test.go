@@ -0,0 +1,233 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+// testContext collects the issues reported by a rule and looks up schemas in
+// a SchemaStore.
+type testContext struct {
+	store *lintschema.SchemaStore
+
+	mu     sync.Mutex
+	issues []lint.Issue
+	errs   []error
+}
+
+func (c *testContext) Context() context.Context {
+	return context.Background()
+}
+
+func (c *testContext) ReportIssue(issue lint.Issue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.issues = append(c.issues, issue)
+}
+
+func (c *testContext) ReportError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+func (c *testContext) GetCRDSchema(gvk schema.GroupVersionKind) *extv1.CustomResourceDefinitionVersion {
+	return c.store.GetCRDSchema(gvk)
+}
+
+func (c *testContext) GetSchemaOrigin(gvk schema.GroupVersionKind) *lint.SchemaOrigin {
+	return c.store.GetSchemaOrigin(gvk)
+}
+
+func (c *testContext) GetSchemaConflicts() []lint.SchemaConflict {
+	return c.store.GetSchemaConflicts()
+}
+
+func (c *testContext) GetKindDefinitions(gk schema.GroupKind) []lint.SchemaOrigin {
+	return c.store.GetKindDefinitions(gk)
+}
+
+func (c *testContext) HasFunction(name string) bool {
+	return c.store.HasFunction(name)
+}
+
+func (c *testContext) GetEnvironmentSchema() *extv1.JSONSchemaProps {
+	return c.store.GetEnvironmentSchema()
+}
+
+// testXRD defines the composite resource example.org/v1 XExample.
+const testXRD = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xexamples.example.org
+spec:
+  group: example.org
+  names:
+    kind: XExample
+    plural: xexamples
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required:
+            - name
+            properties:
+              name:
+                type: string
+              size:
+                type: string
+                default: small
+              parameters:
+                type: object
+                required:
+                - region
+                properties:
+                  region:
+                    type: string
+          status:
+            type: object
+            properties:
+              arn:
+                type: string
+`
+
+// testCRD defines the managed resource s3.aws.upbound.io/v1beta1 Bucket.
+const testCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: buckets.s3.aws.upbound.io
+spec:
+  group: s3.aws.upbound.io
+  names:
+    kind: Bucket
+    plural: buckets
+  scope: Cluster
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        required:
+        - spec
+        properties:
+          spec:
+            type: object
+            required:
+            - forProvider
+            properties:
+              forProvider:
+                type: object
+                required:
+                - region
+                properties:
+                  region:
+                    type: string
+                  tags:
+                    type: object
+                    additionalProperties:
+                      type: string
+                  grants:
+                    type: array
+                    items:
+                      type: string
+          status:
+            type: object
+            properties:
+              atProvider:
+                type: object
+                properties:
+                  arn:
+                    type: string
+`
+
+// testComposition returns a Composition of XExample. spec is inserted into
+// spec and resources into spec.resources, both relative to their parent.
+func testComposition(spec, resources string) string {
+	return `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XExample
+` + indent(spec, "  ") + "  resources:\n" + indent(resources, "  ")
+}
+
+// indent prefixes all lines of s with prefix.
+func indent(s, prefix string) string {
+	if s == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+	for i := range lines {
+		lines[i] = prefix + lines[i]
+	}
+	return strings.Join(lines, "") + "\n"
+}
+
+// newTestPackage parses manifests as package entries with the sources
+// 0.yaml, 1.yaml and so on.
+func newTestPackage(t *testing.T, manifests ...string) *xpkg.Package {
+	t.Helper()
+	pkg := &xpkg.Package{Source: "pkg"}
+	for i, m := range manifests {
+		e, err := parse.ParsePackageEntry([]byte(m), fmt.Sprintf("%d.yaml", i))
+		if err != nil {
+			t.Fatalf("ParsePackageEntry(...): unexpected error: %v", err)
+		}
+		pkg.Entries = append(pkg.Entries, e)
+	}
+	return pkg
+}
+
+// runRule runs rule for pkg with a SchemaStore that contains the schemas of
+// pkg and returns the reported issues.
+func runRule(t *testing.T, rule func(lint.LinterContext, *xpkg.Package), pkg *xpkg.Package) []lint.Issue {
+	t.Helper()
+	store := lintschema.NewSchemaStore()
+	if skipped := store.RegisterPackage(pkg); len(skipped) > 0 {
+		t.Fatalf("RegisterPackage(...): unexpected skipped definitions: %v", skipped)
+	}
+	ctx := &testContext{store: store}
+	rule(ctx, pkg)
+	if len(ctx.errs) > 0 {
+		t.Fatalf("rule(...): unexpected errors: %v", ctx.errs)
+	}
+	return ctx.issues
+}
+
+// formatIssues formats issues as "<source> <path>: <description>" sorted
+// alphabetically.
+func formatIssues(issues []lint.Issue) []string {
+	got := []string{}
+	for _, i := range issues {
+		source := ""
+		if i.Entry != nil {
+			source = i.Entry.Source
+		}
+		got = append(got, fmt.Sprintf("%s %s: %s", source, i.Path.String(), i.Description))
+	}
+	sort.Strings(got)
+	return got
+}
@@ -0,0 +1,266 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/config"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	defaultPluginTimeout = 30 * time.Second
+
+	// Maximum length of the stderr output of a plugin included in errors.
+	maxPluginStderr = 1024
+
+	errPluginCommand          = "plugin has no command"
+	errFmtPluginTimeout       = "invalid timeout '%s'"
+	errMarshalPluginRequest   = "failed to marshal plugin request"
+	errFmtPluginTimedOut      = "plugin timed out after %s"
+	errFmtPluginFailed        = "plugin failed: %s"
+	errFmtPluginFailedStderr  = "plugin failed: %s: %s"
+	errParsePluginResponse    = "failed to parse plugin response"
+	errFmtPluginEntryIndex    = "plugin reported issue for entry %d but the package has %d entries"
+	errFmtPluginIssuePath     = "plugin reported issue with invalid path '%s'"
+	errFmtPluginIssueSeverity = "plugin reported issue with unknown severity '%s'"
+)
+
+// pluginRequest is written to the stdin of a plugin.
+type pluginRequest struct {
+	// Entries of the package.
+	Entries []pluginEntry `json:"entries"`
+
+	// Schemas of all kinds used by the package that are known to the linter.
+	Schemas []pluginSchema `json:"schemas"`
+}
+
+type pluginEntry struct {
+	Source     string `json:"source"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Raw        string `json:"raw"`
+}
+
+type pluginSchema struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Schema     *extv1.JSONSchemaProps `json:"schema"`
+}
+
+// pluginResponse is read from the stdout of a plugin.
+type pluginResponse struct {
+	Issues []pluginIssue `json:"issues"`
+}
+
+type pluginIssue struct {
+	// Rule that discovered the issue.
+	Rule string `json:"rule,omitempty"`
+
+	// Entry is the index of the entry in the request.
+	Entry int `json:"entry"`
+
+	// Path of the issue within the entry, for example spec.resources[0].
+	Path string `json:"path,omitempty"`
+
+	Description string `json:"description"`
+	Severity    string `json:"severity,omitempty"`
+}
+
+// PluginRule runs an external plugin and reports the issues it discovers.
+type PluginRule struct {
+	plugin  config.Plugin
+	timeout time.Duration
+}
+
+// NewPluginRule creates a new PluginRule for plugin.
+func NewPluginRule(plugin config.Plugin) (*PluginRule, error) {
+	if plugin.Command == "" {
+		return nil, errors.New(errPluginCommand)
+	}
+	timeout := defaultPluginTimeout
+	if plugin.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(plugin.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, errors.Errorf(errFmtPluginTimeout, plugin.Timeout)
+		}
+	}
+	if plugin.Name == "" {
+		base := filepath.Base(plugin.Command)
+		plugin.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return &PluginRule{plugin: plugin, timeout: timeout}, nil
+}
+
+// Name of the plugin.
+func (r *PluginRule) Name() string {
+	return r.plugin.Name
+}
+
+// Check runs the plugin for pkg. Failures of the plugin and invalid issues
+// in its response are reported as errors of the linter run.
+func (r *PluginRule) Check(ctx lint.LinterContext, pkg *xpkg.Package) {
+	res, err := r.run(ctx.Context(), buildPluginRequest(ctx, pkg))
+	if err != nil {
+		// The linter run fails with the error of its context anyway.
+		if ctx.Context().Err() == nil {
+			ctx.ReportError(err)
+		}
+		return
+	}
+	for _, iss := range res.Issues {
+		if err := r.reportIssue(ctx, pkg, iss); err != nil {
+			ctx.ReportError(err)
+		}
+	}
+}
+
+func (r *PluginRule) reportIssue(ctx lint.LinterContext, pkg *xpkg.Package, iss pluginIssue) error {
+	if iss.Entry < 0 || iss.Entry >= len(pkg.Entries) {
+		return errors.Errorf(errFmtPluginEntryIndex, iss.Entry, len(pkg.Entries))
+	}
+	issue := lint.Issue{
+		Entry:       &pkg.Entries[iss.Entry],
+		Description: iss.Description,
+	}
+	if iss.Severity != "" {
+		severity, err := lint.ParseSeverity(iss.Severity)
+		if err != nil {
+			return errors.Errorf(errFmtPluginIssueSeverity, iss.Severity)
+		}
+		issue.Severity = severity
+	}
+	if iss.Rule != "" {
+		issue.RuleName = fmt.Sprintf("plugin.%s.%s", r.plugin.Name, iss.Rule)
+	}
+	if iss.Path != "" {
+		path, err := jsonpath.Parse(iss.Path)
+		if err != nil {
+			return errors.Errorf(errFmtPluginIssuePath, iss.Path)
+		}
+		issue.Path = path
+		if value, err := fieldpath.Pave(issue.Entry.Object.Object).GetValue(iss.Path); err == nil {
+			issue.PathValue = fmt.Sprint(value)
+		}
+	}
+	ctx.ReportIssue(issue)
+	return nil
+}
+
+// run the plugin with req.
+func (r *PluginRule) run(ctx context.Context, req pluginRequest) (*pluginResponse, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errMarshalPluginRequest)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.CommandContext(runCtx, r.plugin.Command, r.plugin.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return nil, errors.Errorf(errFmtPluginTimedOut, r.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			if len(msg) > maxPluginStderr {
+				msg = msg[len(msg)-maxPluginStderr:]
+			}
+			return nil, errors.Errorf(errFmtPluginFailedStderr, err, msg)
+		}
+		return nil, errors.Errorf(errFmtPluginFailed, err)
+	}
+
+	res := &pluginResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), res); err != nil {
+		return nil, errors.Wrap(err, errParsePluginResponse)
+	}
+	return res, nil
+}
+
+// buildPluginRequest returns the request for pkg. Includes the schemas of the
+// kinds of all entries, composite types and composed resources of pkg.
+func buildPluginRequest(ctx lint.LinterContext, pkg *xpkg.Package) pluginRequest {
+	req := pluginRequest{
+		Entries: make([]pluginEntry, len(pkg.Entries)),
+		Schemas: []pluginSchema{},
+	}
+	gvks := map[schema.GroupVersionKind]struct{}{}
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		req.Entries[i] = pluginEntry{
+			Source:     e.Source,
+			APIVersion: e.Object.GetAPIVersion(),
+			Kind:       e.Object.GetKind(),
+			Raw:        e.Raw,
+		}
+		gvks[e.Object.GroupVersionKind()] = struct{}{}
+		for _, gvk := range compositionGVKs(e) {
+			gvks[gvk] = struct{}{}
+		}
+	}
+	for gvk := range gvks {
+		crd := ctx.GetCRDSchema(gvk)
+		if crd == nil || crd.Schema == nil {
+			continue
+		}
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+		req.Schemas = append(req.Schemas, pluginSchema{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Schema:     crd.Schema.OpenAPIV3Schema,
+		})
+	}
+	sort.Slice(req.Schemas, func(i, j int) bool {
+		if req.Schemas[i].APIVersion != req.Schemas[j].APIVersion {
+			return req.Schemas[i].APIVersion < req.Schemas[j].APIVersion
+		}
+		return req.Schemas[i].Kind < req.Schemas[j].Kind
+	})
+	return req
+}
+
+// compositionGVKs returns the composite type and the kinds of all composed
+// resources of e if it is a Composition.
+func compositionGVKs(e *xpkg.PackageEntry) []schema.GroupVersionKind {
+	if !e.IsComposition() {
+		return nil
+	}
+	comp, err := e.AsComposition()
+	if err != nil {
+		return nil
+	}
+	gvks := []schema.GroupVersionKind{schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind)}
+	for _, t := range getComposedTemplates(e, comp) {
+		for _, res := range t.Composition.Spec.Resources {
+			base := &unstructured.Unstructured{}
+			if err := base.UnmarshalJSON(res.Base.Raw); err != nil {
+				continue
+			}
+			gvks = append(gvks, base.GroupVersionKind())
+		}
+	}
+	return gvks
+}
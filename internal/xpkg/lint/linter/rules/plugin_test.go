@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/config"
+	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+)
+
+func TestPluginRuleCheck(t *testing.T) {
+	cases := map[string]struct {
+		reason     string
+		script     string
+		timeout    string
+		wantIssues []string
+		wantErrs   []string
+	}{
+		"Issues": {
+			reason:     "Issues of the plugin are reported for the entries of the request.",
+			script:     `cat >/dev/null; echo '{"issues":[{"entry":1,"path":"spec.compositeTypeRef","description":"bad type"}]}'`,
+			wantIssues: []string{"1.yaml .spec.compositeTypeRef: bad type"},
+			wantErrs:   []string{},
+		},
+		"Crash": {
+			reason:     "Plugins that exit with an error fail the run and include their stderr.",
+			script:     `cat >/dev/null; echo broken >&2; exit 3`,
+			wantIssues: []string{},
+			wantErrs:   []string{"plugin failed: exit status 3: broken"},
+		},
+		"Timeout": {
+			reason:     "Plugins that exceed their timeout fail the run.",
+			script:     `exec sleep 5`,
+			timeout:    "100ms",
+			wantIssues: []string{},
+			wantErrs:   []string{"plugin timed out after 100ms"},
+		},
+		"InvalidResponse": {
+			reason:     "Plugins that write no JSON fail the run.",
+			script:     `cat >/dev/null; echo no json`,
+			wantIssues: []string{},
+			wantErrs:   []string{"failed to parse plugin response: invalid character 'o' in literal null (expecting 'u')"},
+		},
+		"EntryOutOfRange": {
+			reason:     "Issues for unknown entries fail the run, the other issues are reported.",
+			script:     `cat >/dev/null; echo '{"issues":[{"entry":5,"description":"lost"},{"entry":0,"description":"found"}]}'`,
+			wantIssues: []string{"0.yaml : found"},
+			wantErrs:   []string{"plugin reported issue for entry 5 but the package has 2 entries"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rule, err := NewPluginRule(config.Plugin{Command: "sh", Args: []string{"-c", tc.script}, Timeout: tc.timeout})
+			if err != nil {
+				t.Fatalf("NewPluginRule(...): unexpected error: %v", err)
+			}
+			pkg := newTestPackage(t, testXRD, testComposition("", ""))
+			ctx := &testContext{store: lintschema.NewSchemaStore()}
+			rule.Check(ctx, pkg)
+
+			if got := formatIssues(ctx.issues); !reflect.DeepEqual(tc.wantIssues, got) {
+				t.Errorf("\n%s\nCheck(...): want issues %q, got %q", tc.reason, tc.wantIssues, got)
+			}
+			gotErrs := []string{}
+			for _, err := range ctx.errs {
+				gotErrs = append(gotErrs, err.Error())
+			}
+			if !reflect.DeepEqual(tc.wantErrs, gotErrs) {
+				t.Errorf("\n%s\nCheck(...): want errors %q, got %q", tc.reason, tc.wantErrs, gotErrs)
+			}
+		})
+	}
+}
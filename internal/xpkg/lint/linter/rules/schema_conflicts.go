@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtSchemaConflict = "conflicting definitions of %s: validated against %s, ignoring %s"
+)
+
+// CheckSchemaConflicts reports kinds that are defined more than once with
+// different schemas, for example by two versions of the same provider. The
+// issue points to the definition of the linted package if there is one, else
+// to the ignored definition, and lists the other one as related.
+func CheckSchemaConflicts(ctx lint.LinterContext, pkg *xpkg.Package) {
+	inspector, ok := ctx.(lint.SchemaInspector)
+	if !ok {
+		return
+	}
+	sources := map[string]bool{}
+	for _, e := range pkg.Entries {
+		sources[e.Source] = true
+	}
+	for _, c := range inspector.GetSchemaConflicts() {
+		at, other := c.Ignored, c.Used
+		if !isPackageOrigin(at, sources) && isPackageOrigin(other, sources) {
+			at, other = other, at
+		}
+		if at.Entry == nil {
+			continue
+		}
+		issue := lint.Issue{
+			Entry:       at.Entry,
+			Path:        jsonpath.NewJSONPath("spec"),
+			Description: fmt.Sprintf(errFmtSchemaConflict, c.GVK.String(), c.Used.String(), c.Ignored.String()),
+		}
+		if other.Entry != nil {
+			issue.Related = []lint.Location{{Entry: other.Entry, Path: jsonpath.NewJSONPath("spec")}}
+		}
+		ctx.ReportIssue(issue)
+	}
+}
+
+// isPackageOrigin returns true if origin is a manifest of the linted package
+// with the entry sources.
+func isPackageOrigin(origin lint.SchemaOrigin, sources map[string]bool) bool {
+	return origin.Entry != nil && sources[origin.Entry.Source]
+}
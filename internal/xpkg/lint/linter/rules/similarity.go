@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"sort"
+	"strings"
+)
+
+// Maximum number of suggestions for an unknown name.
+const maxSuggestions = 3
+
+// similarNames returns up to maxSuggestions candidates that are similar to
+// name, most similar first. See allSimilarNames.
+func similarNames(name string, candidates []string) []string {
+	names := allSimilarNames(name, candidates)
+	if len(names) > maxSuggestions {
+		names = names[:maxSuggestions]
+	}
+	return names
+}
+
+// allSimilarNames returns all candidates that are similar to name, most
+// similar first. Names are compared case insensitive and without '_' and
+// '-', so snake case, kebab case and camel case variants of a name are equal.
+func allSimilarNames(name string, candidates []string) []string {
+	type scored struct {
+		name     string
+		distance int
+	}
+	normalized := normalizeName(name)
+	maxDistance := maxNameDistance(normalized)
+	matches := []scored{}
+	for _, c := range candidates {
+		if d := levenshtein(normalized, normalizeName(c)); d <= maxDistance {
+			matches = append(matches, scored{name: c, distance: d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+// maxNameDistance returns the maximum edit distance of names that are
+// considered similar to name. Short names allow less edits to avoid
+// suggesting unrelated names.
+func maxNameDistance(name string) int {
+	switch n := len(name); {
+	case n < 5:
+		return 1
+	case n < 10:
+		return 2
+	}
+	return 3
+}
+
+// isTightMatch returns true if name and candidate only differ in case or by
+// a single edit, so replacing name with candidate is an unambiguous fix.
+func isTightMatch(name, candidate string) bool {
+	return strings.EqualFold(name, candidate) || levenshtein(name, candidate) <= 1
+}
+
+func normalizeName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// levenshtein returns the edit distance of a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// formatSuggestions formats names as "'a', 'b' or 'c'".
+func formatSuggestions(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = "'" + n + "'"
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+func minInt(first int, others ...int) int {
+	m := first
+	for _, o := range others {
+		if o < m {
+			m = o
+		}
+	}
+	return m
+}
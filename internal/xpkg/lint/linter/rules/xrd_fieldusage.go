@@ -0,0 +1,355 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/stats"
+)
+
+const (
+	errParseXRDSchema = "failed to parse schema of version %s"
+
+	errFmtSpecFieldUnused      = "spec field '%s' is never consumed by any composition"
+	errFmtStatusFieldUnwritten = "status field '%s' is never populated by any composition"
+
+	wildcardSegment = "*"
+)
+
+// fieldUsage tracks which fields of a composite resource version are read and
+// written by compositions.
+type fieldUsage struct {
+	reads  [][]string
+	writes [][]string
+
+	// unknown is true if a composition runs functions other than
+	// function-patch-and-transform, which may read and write any field.
+	unknown bool
+}
+
+// CheckXRDFieldUsage checks if the spec fields of each XRD version are
+// consumed by at least one composition and if the status fields are populated
+// by at least one composition. The coverage of each version is part of the
+// stats of a package, see ComputeXRDFieldUsage.
+func CheckXRDFieldUsage(ctx lint.LinterContext, pkg *xpkg.Package) {
+	forEachXRDVersionUsage(pkg, ctx.ReportIssue, func(e *xpkg.PackageEntry, gvk schema.GroupVersionKind, versionIndex int, props *extv1.JSONSchemaProps, usage *fieldUsage) {
+		versionFieldUsage(gvk, versionIndex, props, usage, func(format, field string, path jsonpath.JSONPath) {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        path,
+				Description: fmt.Sprintf(format, field),
+			})
+		})
+	})
+}
+
+// ComputeXRDFieldUsage returns the field usage of each XRD version of pkg
+// that is implemented by a composition of pkg, sorted by composite type.
+func ComputeXRDFieldUsage(pkg *xpkg.Package) []stats.FieldUsage {
+	usages := []stats.FieldUsage{}
+	forEachXRDVersionUsage(pkg, func(lint.Issue) {}, func(_ *xpkg.PackageEntry, gvk schema.GroupVersionKind, versionIndex int, props *extv1.JSONSchemaProps, usage *fieldUsage) {
+		usages = append(usages, versionFieldUsage(gvk, versionIndex, props, usage, func(string, string, jsonpath.JSONPath) {}))
+	})
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].CompositeType < usages[j].CompositeType
+	})
+	return usages
+}
+
+// forEachXRDVersionUsage calls fn with the schema and field usage of each
+// XRD version of pkg that is implemented by a composition of pkg. Versions
+// whose field usage is unknown are skipped. XRDs and schemas that cannot be
+// parsed are passed to report.
+func forEachXRDVersionUsage(pkg *xpkg.Package, report func(lint.Issue), fn func(e *xpkg.PackageEntry, gvk schema.GroupVersionKind, versionIndex int, props *extv1.JSONSchemaProps, usage *fieldUsage)) {
+	usages := collectFieldUsages(pkg)
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsXRD() {
+			continue
+		}
+		xrd, err := e.AsXRD()
+		if err != nil {
+			report(lint.Issue{
+				Severity:    lint.SeverityError,
+				Entry:       e,
+				Description: errors.Wrapf(err, errConvertTo, "XRD").Error(),
+			})
+			continue
+		}
+		for vi, v := range xrd.Spec.Versions {
+			gvk := schema.GroupVersionKind{Group: xrd.Spec.Group, Version: v.Name, Kind: xrd.Spec.Names.Kind}
+			usage, exists := usages[gvk]
+			if !exists || v.Schema == nil {
+				// Compositions for this XRD may be part of another package.
+				continue
+			}
+			if usage.unknown {
+				continue
+			}
+			props := &extv1.JSONSchemaProps{}
+			if err := json.Unmarshal(v.Schema.OpenAPIV3Schema.Raw, props); err != nil {
+				report(lint.Issue{
+					Severity:    lint.SeverityError,
+					Entry:       e,
+					Path:        jsonpath.NewJSONPath("spec", "versions", vi, "schema", "openAPIV3Schema"),
+					Description: errors.Wrapf(err, errParseXRDSchema, v.Name).Error(),
+				})
+				continue
+			}
+			fn(e, gvk, vi, props, usage)
+		}
+	}
+}
+
+// versionFieldUsage walks the spec and status fields of the XRD version
+// schema props and calls report with errFmtSpecFieldUnused or
+// errFmtStatusFieldUnwritten for every field that is not covered by usage.
+func versionFieldUsage(gvk schema.GroupVersionKind, versionIndex int, props *extv1.JSONSchemaProps, usage *fieldUsage, report func(format, field string, path jsonpath.JSONPath)) stats.FieldUsage {
+	basePath := jsonpath.NewJSONPath("spec", "versions", versionIndex, "schema", "openAPIV3Schema", "properties")
+	u := stats.FieldUsage{CompositeType: gvk.String()}
+	if spec, exists := props.Properties["spec"]; exists {
+		u.SpecFields, u.SpecFieldsConsumed = walkFieldUsage(&spec, []string{"spec"}, jsonpath.NewJSONPath(basePath, "spec"), usage.reads, func(field string, path jsonpath.JSONPath) {
+			report(errFmtSpecFieldUnused, field, path)
+		})
+	}
+	if status, exists := props.Properties["status"]; exists {
+		u.StatusFields, u.StatusFieldsPopulated = walkFieldUsage(&status, []string{"status"}, jsonpath.NewJSONPath(basePath, "status"), usage.writes, func(field string, path jsonpath.JSONPath) {
+			report(errFmtStatusFieldUnwritten, field, path)
+		})
+	}
+	return u
+}
+
+// walkFieldUsage walks the properties of props and reports every field that is
+// not covered by any of the used paths. A field is covered if it, one of its
+// parents or one of its children is used. Returns the number of leaf fields
+// and the number of used leaf fields below props.
+func walkFieldUsage(props *extv1.JSONSchemaProps, field []string, path jsonpath.JSONPath, used [][]string, report func(field string, path jsonpath.JSONPath)) (total, usedTotal int) {
+	names := make([]string, 0, len(props.Properties))
+	for name := range props.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := props.Properties[name]
+		propField := append(append([]string{}, field...), name)
+		propPath := jsonpath.NewJSONPath(path, "properties", name)
+		t, u := walkPropFieldUsage(&prop, propField, propPath, used, report)
+		total += t
+		usedTotal += u
+	}
+	return total, usedTotal
+}
+
+func walkPropFieldUsage(prop *extv1.JSONSchemaProps, field []string, path jsonpath.JSONPath, used [][]string, report func(field string, path jsonpath.JSONPath)) (total, usedTotal int) {
+	children, childPath, childField := prop, path, field
+	if prop.Type == "array" && prop.Items != nil && prop.Items.Schema != nil {
+		children = prop.Items.Schema
+		childPath = jsonpath.NewJSONPath(path, "items")
+		childField = append(append([]string{}, field...), wildcardSegment)
+	}
+	isLeaf := len(children.Properties) == 0
+	leaves := 1
+	if !isLeaf {
+		leaves = countLeafFields(children)
+	}
+
+	switch fieldUsageOf(field, used) {
+	case fieldUnused:
+		report(formatFieldPath(field), path)
+		return leaves, 0
+	case fieldUsedFully:
+		return leaves, leaves
+	}
+	if isLeaf {
+		return 1, 1
+	}
+	return walkFieldUsage(children, childField, childPath, used, report)
+}
+
+func countLeafFields(props *extv1.JSONSchemaProps) int {
+	count := 0
+	for _, prop := range props.Properties {
+		p := prop
+		if p.Type == "array" && p.Items != nil && p.Items.Schema != nil {
+			p = *p.Items.Schema
+		}
+		if len(p.Properties) == 0 {
+			count++
+		} else {
+			count += countLeafFields(&p)
+		}
+	}
+	return count
+}
+
+type fieldUsageType int
+
+const (
+	fieldUnused fieldUsageType = iota
+	fieldUsedPartially
+	fieldUsedFully
+)
+
+func fieldUsageOf(field []string, used [][]string) fieldUsageType {
+	usage := fieldUnused
+	for _, u := range used {
+		switch {
+		case isFieldPrefix(u, field):
+			return fieldUsedFully
+		case isFieldPrefix(field, u):
+			usage = fieldUsedPartially
+		}
+	}
+	return usage
+}
+
+// isFieldPrefix determines if prefix is a prefix of field.
+func isFieldPrefix(prefix, field []string) bool {
+	if len(prefix) > len(field) {
+		return false
+	}
+	for i, s := range prefix {
+		if s != field[i] && s != wildcardSegment && field[i] != wildcardSegment {
+			return false
+		}
+	}
+	return true
+}
+
+// collectFieldUsages returns the composite fields that are read and written by
+// the compositions in pkg grouped by composite type.
+func collectFieldUsages(pkg *xpkg.Package) map[schema.GroupVersionKind]*fieldUsage {
+	usages := map[schema.GroupVersionKind]*fieldUsage{}
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(comp.Spec.CompositeTypeRef.APIVersion)
+		if err != nil {
+			continue
+		}
+		gvk := gv.WithKind(comp.Spec.CompositeTypeRef.Kind)
+		usage, exists := usages[gvk]
+		if !exists {
+			usage = &fieldUsage{}
+			usages[gvk] = usage
+		}
+		for _, t := range getComposedTemplates(e, comp) {
+			for ri := range t.Composition.Spec.Resources {
+				for _, p := range resolveResourcePatches(t, ri) {
+					usage.add(p.Patch)
+				}
+			}
+		}
+		ext, err := e.AsCompositionExtensions()
+		if err != nil {
+			continue
+		}
+		if ext.Spec.Environment != nil {
+			usage.addEnvironment(ext.Spec.Environment)
+		}
+		if len(ext.Spec.Functions) > 0 {
+			usage.unknown = true
+		}
+		for i := range ext.Spec.Pipeline {
+			if !ext.Spec.Pipeline[i].IsPatchAndTransformInput() {
+				usage.unknown = true
+			}
+		}
+	}
+	return usages
+}
+
+func (u *fieldUsage) add(p xpv1.Patch) {
+	switch p.Type {
+	case "", xpv1.PatchTypeFromCompositeFieldPath:
+		u.reads = appendFieldPath(u.reads, p.FromFieldPath)
+	case xpv1.PatchTypeCombineFromComposite:
+		if p.Combine != nil {
+			for _, v := range p.Combine.Variables {
+				u.reads = appendFieldPath(u.reads, &v.FromFieldPath)
+			}
+		}
+	case xpv1.PatchTypeToCompositeFieldPath:
+		toFieldPath := p.ToFieldPath
+		if toFieldPath == nil {
+			toFieldPath = p.FromFieldPath
+		}
+		u.writes = appendFieldPath(u.writes, toFieldPath)
+	case xpv1.PatchTypeCombineToComposite:
+		u.writes = appendFieldPath(u.writes, p.ToFieldPath)
+	}
+}
+
+// addEnvironment adds the composite fields used by the environment patches and
+// selectors of env. The environment takes the role of the composed resource.
+func (u *fieldUsage) addEnvironment(env *xpkg.EnvironmentConfiguration) {
+	for _, p := range env.Patches {
+		u.add(p)
+	}
+	for _, s := range env.EnvironmentConfigs {
+		if s.Selector == nil {
+			continue
+		}
+		for _, m := range s.Selector.MatchLabels {
+			u.reads = appendFieldPath(u.reads, m.ValueFromFieldPath)
+		}
+	}
+}
+
+// formatFieldPath formats normalized field segments as field path.
+func formatFieldPath(field []string) string {
+	b := strings.Builder{}
+	for i, s := range field {
+		switch {
+		case s == wildcardSegment:
+			b.WriteString("[*]")
+		case i > 0:
+			b.WriteString("." + s)
+		default:
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+// appendFieldPath appends the normalized segments of rawPath to paths. Array
+// indices are replaced by wildcards. Invalid paths are ignored as they are
+// reported by composition.checkPathFieldPaths.
+func appendFieldPath(paths [][]string, rawPath *string) [][]string {
+	if rawPath == nil {
+		return paths
+	}
+	segments, err := fieldpath.Parse(*rawPath)
+	if err != nil {
+		return paths
+	}
+	path := make([]string, len(segments))
+	for i, s := range segments {
+		if s.Type == fieldpath.SegmentIndex {
+			path[i] = wildcardSegment
+		} else {
+			path[i] = s.Field
+		}
+	}
+	return append(paths, path)
+}
@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckXRDFieldUsage(t *testing.T) {
+	resources := `- name: bucket
+  base:
+    apiVersion: s3.aws.upbound.io/v1beta1
+    kind: Bucket
+  patches:
+  - fromFieldPath: spec.name
+    toFieldPath: metadata.name
+  - fromFieldPath: spec.parameters
+    toFieldPath: spec.forProvider
+  - type: ToCompositeFieldPath
+    fromFieldPath: status.atProvider.arn
+    toFieldPath: status.arn
+`
+	ptStep := `- step: patch-and-transform
+  functionRef:
+    name: function-patch-and-transform
+  input:
+    apiVersion: pt.fn.crossplane.io/v1beta1
+    kind: Resources
+    resources:
+` + indent(resources, "    ")
+	templateStep := `- step: render
+  functionRef:
+    name: function-go-templating
+  input:
+    apiVersion: gotemplating.fn.crossplane.io/v1beta1
+    kind: GoTemplate
+    source: Inline
+`
+	cases := map[string]struct {
+		reason    string
+		manifests []string
+		want      []string
+	}{
+		"Resources": {
+			reason:    "Fields not read or written by patches of resources are reported.",
+			manifests: []string{testXRD, testComposition("", resources)},
+			want:      []string{"0.yaml .spec.versions[0].schema.openAPIV3Schema.properties.spec.properties.size: spec field 'spec.size' is never consumed by any composition"},
+		},
+		"PatchAndTransformPipeline": {
+			reason:    "Fields not read or written by function-patch-and-transform steps are reported.",
+			manifests: []string{testXRD, testComposition("mode: Pipeline\npipeline:\n"+indent(ptStep, "  "), "")},
+			want:      []string{"0.yaml .spec.versions[0].schema.openAPIV3Schema.properties.spec.properties.size: spec field 'spec.size' is never consumed by any composition"},
+		},
+		"OtherFunctionPipeline": {
+			reason:    "Fields of XRDs whose compositions run other functions may be used by them and are not reported.",
+			manifests: []string{testXRD, testComposition("mode: Pipeline\npipeline:\n"+indent(ptStep+templateStep, "  "), "")},
+			want:      []string{},
+		},
+		"Functions": {
+			reason:    "Fields of XRDs whose compositions run functions of spec.functions may be used by them and are not reported.",
+			manifests: []string{testXRD, testComposition("functions:\n- name: render\n  type: Container\n  container:\n    image: xpkg.upbound.io/example/render\n", resources)},
+			want:      []string{},
+		},
+		"NoComposition": {
+			reason:    "XRDs without composition in the package are not reported.",
+			manifests: []string{testXRD},
+			want:      []string{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := formatIssues(runRule(t, CheckXRDFieldUsage, newTestPackage(t, tc.manifests...)))
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nCheckXRDFieldUsage(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
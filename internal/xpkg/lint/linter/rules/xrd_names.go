@@ -0,0 +1,316 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+	lintschema "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+)
+
+const (
+	errFmtXRDName           = "name must be '%s' (<plural>.<group>)"
+	errFmtFixXRDName        = "rename to '%s'"
+	errFmtInvalidGroup      = "invalid group: %s"
+	errFmtInvalidNamesField = "invalid %s: %s"
+	errFmtClaimNameConflict = "claim %s '%s' conflicts with the %s of the composite resource"
+	errFmtKindDefinedBy     = "kind %s is also defined by %s"
+	errFmtPluralDefinedBy   = "resource %s is also defined by %s"
+	errBuildCompositeCRD    = "failed to build composite CRD"
+	errBuildClaimCRD        = "failed to build claim CRD"
+)
+
+// Indexes of packageDefinitions.
+const (
+	definitionsByKind     = "kind"
+	definitionsByResource = "resource"
+)
+
+// packageDefinition is a kind defined by a CRD or XRD of the package.
+type packageDefinition struct {
+	Entry *xpkg.PackageEntry
+
+	// Path of the names of the definition within the entry.
+	Path jsonpath.JSONPath
+}
+
+func (d packageDefinition) String() string {
+	if d.Entry.IsXRD() {
+		return "XRD " + d.Entry.Object.GetName()
+	}
+	return "CRD " + d.Entry.Object.GetName()
+}
+
+// packageDefinitions indexes the kinds and resources (<plural>.<group>)
+// defined by the CRDs and XRDs of a package.
+type packageDefinitions map[string]map[string][]packageDefinition
+
+func (d packageDefinitions) add(index, key string, def packageDefinition) {
+	if d[index] == nil {
+		d[index] = map[string][]packageDefinition{}
+	}
+	d[index][key] = append(d[index][key], def)
+}
+
+// others returns the definitions of key by entries that precede e in the
+// order of getPackageDefinitions, so a collision is reported once for the
+// later definition. Collisions of the
+// claim and composite names of an XRD are reported by checkClaimNames.
+func (d packageDefinitions) others(index, key string, e *xpkg.PackageEntry) []packageDefinition {
+	others := []packageDefinition{}
+	for _, def := range d[index][key] {
+		if def.Entry == e {
+			break
+		}
+		others = append(others, def)
+	}
+	return others
+}
+
+func getPackageDefinitions(pkg *xpkg.Package) packageDefinitions {
+	defs := packageDefinitions{}
+	addNames := func(e *xpkg.PackageEntry, path jsonpath.JSONPath, group, kind, plural string) {
+		def := packageDefinition{Entry: e, Path: path}
+		defs.add(definitionsByKind, schema.GroupKind{Group: group, Kind: kind}.String(), def)
+		defs.add(definitionsByResource, plural+"."+group, def)
+	}
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		switch {
+		case e.IsCRD():
+			crd, err := e.AsCRD()
+			if err != nil {
+				continue
+			}
+			addNames(e, jsonpath.NewJSONPath("spec", "names"), crd.Spec.Group, crd.Spec.Names.Kind, crd.Spec.Names.Plural)
+		case e.IsXRD():
+			xrd, err := e.AsXRD()
+			if err != nil {
+				continue
+			}
+			addNames(e, jsonpath.NewJSONPath("spec", "names"), xrd.Spec.Group, xrd.Spec.Names.Kind, xrd.Spec.Names.Plural)
+			if xrd.Spec.ClaimNames != nil {
+				addNames(e, jsonpath.NewJSONPath("spec", "claimNames"), xrd.Spec.Group, xrd.Spec.ClaimNames.Kind, xrd.Spec.ClaimNames.Plural)
+			}
+		}
+	}
+	// Entries are parsed concurrently, so they are sorted to report
+	// collisions for the same definition on every run.
+	for _, index := range defs {
+		for _, list := range index {
+			sort.SliceStable(list, func(i, j int) bool {
+				a, b := list[i].Entry, list[j].Entry
+				if a.Source != b.Source {
+					return a.Source < b.Source
+				}
+				return a.Object.GetName() < b.Object.GetName()
+			})
+		}
+	}
+	return defs
+}
+
+// CheckXRDNames checks the names of XRDs: metadata.name must be
+// <plural>.<group>, the group and names must be valid, claim names must
+// differ from the composite names and the kinds and resources must not be
+// defined by another CRD or XRD of the package or its dependencies. XRDs
+// whose composite or claim CRD cannot be built are reported as well.
+func CheckXRDNames(ctx lint.LinterContext, pkg *xpkg.Package) {
+	defs := getPackageDefinitions(pkg)
+	sources := map[string]bool{}
+	for _, e := range pkg.Entries {
+		sources[e.Source] = true
+	}
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsXRD() {
+			continue
+		}
+		xrd, err := e.AsXRD()
+		if err != nil {
+			// Reported by composition.checkCompositeType.
+			continue
+		}
+		checkXRDName(ctx, e, xrd)
+		checkXRDNamesFields(ctx, e, jsonpath.NewJSONPath("spec", "names"), xrd.Spec.Names.Plural, xrd.Spec.Names.Singular)
+		checkXRDDefinitions(ctx, e, xrd, jsonpath.NewJSONPath("spec", "names"), xrd.Spec.Names.Kind, xrd.Spec.Names.Plural, defs, sources)
+		if _, err := lintschema.ForCompositeResource(xrd); err != nil {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath("spec", "versions"),
+				Description: errors.Wrap(err, errBuildCompositeCRD).Error(),
+			})
+		}
+		if xrd.Spec.ClaimNames == nil {
+			continue
+		}
+		claimPath := jsonpath.NewJSONPath("spec", "claimNames")
+		checkXRDNamesFields(ctx, e, claimPath, xrd.Spec.ClaimNames.Plural, xrd.Spec.ClaimNames.Singular)
+		if !checkClaimNames(ctx, e, xrd) {
+			continue
+		}
+		checkXRDDefinitions(ctx, e, xrd, claimPath, xrd.Spec.ClaimNames.Kind, xrd.Spec.ClaimNames.Plural, defs, sources)
+		if _, err := lintschema.ForCompositeResourceClaim(xrd); err != nil {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath("spec", "versions"),
+				Description: errors.Wrap(err, errBuildClaimCRD).Error(),
+			})
+		}
+	}
+}
+
+func checkXRDName(ctx lint.LinterContext, e *xpkg.PackageEntry, xrd *xpv1.CompositeResourceDefinition) {
+	if errs := validation.IsDNS1123Subdomain(xrd.Spec.Group); len(errs) > 0 {
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        jsonpath.NewJSONPath("spec", "group"),
+			PathValue:   xrd.Spec.Group,
+			Description: fmt.Sprintf(errFmtInvalidGroup, strings.Join(errs, "; ")),
+		})
+		return
+	}
+	expected := xrd.Spec.Names.Plural + "." + xrd.Spec.Group
+	if xrd.Spec.Names.Plural == "" || xrd.GetName() == expected {
+		return
+	}
+	path := jsonpath.NewJSONPath("metadata", "name")
+	ctx.ReportIssue(lint.Issue{
+		Entry:       e,
+		Path:        path,
+		PathValue:   xrd.GetName(),
+		Description: fmt.Sprintf(errFmtXRDName, expected),
+		Fixes: []lint.Fix{{
+			Type:        lint.FixTypeSetValue,
+			Description: fmt.Sprintf(errFmtFixXRDName, expected),
+			Path:        path,
+			Value:       expected,
+		}},
+	})
+}
+
+// checkXRDNamesFields checks if the plural and singular names at path are
+// valid DNS labels.
+func checkXRDNamesFields(ctx lint.LinterContext, e *xpkg.PackageEntry, path jsonpath.JSONPath, plural, singular string) {
+	fields := []struct {
+		name, value string
+		required    bool
+	}{
+		{"plural", plural, true},
+		{"singular", singular, false},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			if f.required {
+				ctx.ReportIssue(lint.Issue{
+					Entry:       e,
+					Path:        path,
+					Description: "require field " + f.name,
+				})
+			}
+			continue
+		}
+		if errs := validation.IsDNS1035Label(f.value); len(errs) > 0 {
+			ctx.ReportIssue(lint.Issue{
+				Entry:       e,
+				Path:        jsonpath.NewJSONPath(path, f.name),
+				PathValue:   f.value,
+				Description: fmt.Sprintf(errFmtInvalidNamesField, f.name, strings.Join(errs, "; ")),
+			})
+		}
+	}
+}
+
+// checkClaimNames reports claim names that equal the composite names.
+// Returns false if an issue was reported.
+func checkClaimNames(ctx lint.LinterContext, e *xpkg.PackageEntry, xrd *xpv1.CompositeResourceDefinition) bool {
+	names, claimNames := xrd.Spec.Names, xrd.Spec.ClaimNames
+	fields := []struct {
+		name, claim, composite string
+	}{
+		{"kind", claimNames.Kind, names.Kind},
+		{"plural", claimNames.Plural, names.Plural},
+		{"singular", claimNames.Singular, names.Singular},
+		{"listKind", claimNames.ListKind, names.ListKind},
+	}
+	valid := true
+	for _, f := range fields {
+		if f.claim == "" || f.claim != f.composite {
+			continue
+		}
+		valid = false
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        jsonpath.NewJSONPath("spec", "claimNames", f.name),
+			PathValue:   f.claim,
+			Description: fmt.Sprintf(errFmtClaimNameConflict, f.name, f.claim, f.name),
+		})
+	}
+	return valid
+}
+
+// checkXRDDefinitions reports if the kind or the resource <plural>.<group>
+// of the names at path are defined by another CRD or XRD of the package or,
+// for kinds, of a dependency.
+func checkXRDDefinitions(ctx lint.LinterContext, e *xpkg.PackageEntry, xrd *xpv1.CompositeResourceDefinition, path jsonpath.JSONPath, kind, plural string, defs packageDefinitions, sources map[string]bool) {
+	gk := schema.GroupKind{Group: xrd.Spec.Group, Kind: kind}
+	if others := defs.others(definitionsByKind, gk.String(), e); len(others) > 0 {
+		reportDefinedBy(ctx, e, jsonpath.NewJSONPath(path, "kind"), kind, fmt.Sprintf(errFmtKindDefinedBy, gk, definitionNames(others)), others)
+	} else if origin := dependencyOrigin(ctx, gk, sources); origin != nil {
+		ctx.ReportIssue(lint.Issue{
+			Entry:       e,
+			Path:        jsonpath.NewJSONPath(path, "kind"),
+			PathValue:   kind,
+			Description: fmt.Sprintf(errFmtKindDefinedBy, gk, origin),
+		})
+	}
+	resource := plural + "." + xrd.Spec.Group
+	if others := defs.others(definitionsByResource, resource, e); plural != "" && len(others) > 0 {
+		reportDefinedBy(ctx, e, jsonpath.NewJSONPath(path, "plural"), plural, fmt.Sprintf(errFmtPluralDefinedBy, resource, definitionNames(others)), others)
+	}
+}
+
+func reportDefinedBy(ctx lint.LinterContext, e *xpkg.PackageEntry, path jsonpath.JSONPath, value, description string, others []packageDefinition) {
+	related := make([]lint.Location, len(others))
+	for i, o := range others {
+		related[i] = lint.Location{Entry: o.Entry, Path: o.Path}
+	}
+	ctx.ReportIssue(lint.Issue{
+		Entry:       e,
+		Path:        path,
+		PathValue:   value,
+		Related:     related,
+		Description: description,
+	})
+}
+
+func definitionNames(defs []packageDefinition) string {
+	names := make([]string, len(defs))
+	for i, d := range defs {
+		names[i] = d.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// dependencyOrigin returns the first manifest that defines gk and is not
+// part of the package, such as a CRD of a provider, or nil if there is none.
+func dependencyOrigin(ctx lint.LinterContext, gk schema.GroupKind, sources map[string]bool) *lint.SchemaOrigin {
+	inspector, ok := ctx.(lint.SchemaInspector)
+	if !ok {
+		return nil
+	}
+	for _, origin := range inspector.GetKindDefinitions(gk) {
+		if !sources[origin.Entry.Source] {
+			return &origin
+		}
+	}
+	return nil
+}
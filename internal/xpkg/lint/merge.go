@@ -0,0 +1,71 @@
+package lint
+
+import "github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+
+// mergeKey identifies issues that are merged. Entries are compared by
+// identity, as the entries of a package image share the same source.
+type mergeKey struct {
+	rule        string
+	entry       *xpkg.PackageEntry
+	path        string
+	pathValue   string
+	description string
+}
+
+// locationKey identifies a location by entry identity and path.
+type locationKey struct {
+	entry *xpkg.PackageEntry
+	path  string
+}
+
+// Merge combines issues that have the same rule, entry, path and description
+// into one issue. The related locations of merged issues are added to the
+// related locations of the first one, so an issue that is caused by a single
+// root cause, for example a broken patch of a shared patch set, is reported
+// once. The order of issues is kept.
+func Merge(issues []Issue) []Issue {
+	merged := make([]Issue, 0, len(issues))
+	index := make(map[mergeKey]int, len(issues))
+	for _, iss := range issues {
+		key := mergeKey{
+			rule:        iss.RuleName,
+			entry:       iss.Entry,
+			path:        iss.Path.String(),
+			pathValue:   iss.PathValue,
+			description: iss.Description,
+		}
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(merged)
+			iss.Related = appendLocations(nil, iss.Related...)
+			merged = append(merged, iss)
+			continue
+		}
+		if iss.Entry != merged[i].Entry || iss.Path.String() != merged[i].Path.String() {
+			merged[i].Related = appendLocations(merged[i].Related, Location{Entry: iss.Entry, Path: iss.Path})
+		}
+		merged[i].Related = appendLocations(merged[i].Related, iss.Related...)
+	}
+	return merged
+}
+
+// appendLocations appends the locations that are not yet part of locations.
+func appendLocations(locations []Location, add ...Location) []Location {
+	for _, l := range add {
+		exists := false
+		for _, o := range locations {
+			if o.key() == l.key() {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			locations = append(locations, l)
+		}
+	}
+	return locations
+}
+
+func (l Location) key() locationKey {
+	return locationKey{entry: l.Entry, path: l.Path.String()}
+}
@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+func TestMerge(t *testing.T) {
+	// Entries of a package image share the same source.
+	a := &xpkg.PackageEntry{Source: "registry/pkg:v1"}
+	b := &xpkg.PackageEntry{Source: "registry/pkg:v1"}
+	path := jsonpath.NewJSONPath("spec", "resources", 0)
+	relatedA := Location{Entry: a, Path: jsonpath.NewJSONPath("spec", "resources", 1)}
+	relatedB := Location{Entry: a, Path: jsonpath.NewJSONPath("spec", "resources", 2)}
+
+	cases := map[string]struct {
+		reason string
+		issues []Issue
+		want   []Issue
+	}{
+		"SameEntry": {
+			reason: "Issues of the same entry and path are merged and keep the related locations of both.",
+			issues: []Issue{
+				{RuleName: "r", Entry: a, Path: path, Description: "d", Related: []Location{relatedA}},
+				{RuleName: "r", Entry: a, Path: path, Description: "d", Related: []Location{relatedA, relatedB}},
+			},
+			want: []Issue{
+				{RuleName: "r", Entry: a, Path: path, Description: "d", Related: []Location{relatedA, relatedB}},
+			},
+		},
+		"DifferentEntriesSameSource": {
+			reason: "Issues of different entries are not merged even if the entries share the source.",
+			issues: []Issue{
+				{RuleName: "r", Entry: a, Path: path, Description: "d"},
+				{RuleName: "r", Entry: b, Path: path, Description: "d"},
+			},
+			want: []Issue{
+				{RuleName: "r", Entry: a, Path: path, Description: "d"},
+				{RuleName: "r", Entry: b, Path: path, Description: "d"},
+			},
+		},
+		"DifferentDescription": {
+			reason: "Issues with different descriptions are not merged.",
+			issues: []Issue{
+				{RuleName: "r", Entry: a, Path: path, Description: "d1"},
+				{RuleName: "r", Entry: a, Path: path, Description: "d2"},
+			},
+			want: []Issue{
+				{RuleName: "r", Entry: a, Path: path, Description: "d1"},
+				{RuleName: "r", Entry: a, Path: path, Description: "d2"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Merge(tc.issues)
+			if len(got) != len(tc.want) {
+				t.Fatalf("\n%s\nMerge(...): want %d issues, got %d", tc.reason, len(tc.want), len(got))
+			}
+			for i := range got {
+				w, g := tc.want[i], got[i]
+				if g.Entry != w.Entry || g.Path.String() != w.Path.String() || g.Description != w.Description {
+					t.Errorf("\n%s\nMerge(...)[%d]: want %s%s %q, got %s%s %q", tc.reason, i, w.Entry.Source, w.Path, w.Description, g.Entry.Source, g.Path, g.Description)
+				}
+				if len(g.Related) != len(w.Related) {
+					t.Fatalf("\n%s\nMerge(...)[%d].Related: want %d locations, got %d", tc.reason, i, len(w.Related), len(g.Related))
+				}
+				for j := range g.Related {
+					if g.Related[j].key() != w.Related[j].key() {
+						t.Errorf("\n%s\nMerge(...)[%d].Related[%d]: want %s, got %s", tc.reason, i, j, w.Related[j].Path, g.Related[j].Path)
+					}
+				}
+			}
+		})
+	}
+}
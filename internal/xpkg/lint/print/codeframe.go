@@ -0,0 +1,239 @@
+package print
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gookit/color"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+const (
+	// Number of lines shown before and after the line of an issue.
+	codeFrameContextLines = 2
+
+	// Header of issues that do not belong to a file.
+	packageIssuesHeader = "package"
+)
+
+var _ Printer = &CodeFramePrinter{}
+
+// CodeFramePrinter prints issues grouped by file and sorted by line. Each
+// issue is followed by the lines of the manifest around it with a caret under
+// the offending value. The report ends with a summary table by rule.
+type CodeFramePrinter struct {
+	out    io.Writer
+	colors bool
+}
+
+// NewCodeFramePrinter creates a new CodeFramePrinter. Colors are only used if
+// out is a terminal and NO_COLOR is not set.
+func NewCodeFramePrinter(out io.Writer) *CodeFramePrinter {
+	return &CodeFramePrinter{
+		out:    out,
+		colors: color.Enable && isTerminal(out),
+	}
+}
+
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// locatedIssue is an issue together with its resolved position.
+type locatedIssue struct {
+	lint.Issue
+	line   int
+	column int
+}
+
+func (p *CodeFramePrinter) PrintReport(report lint.LinterReport) error {
+	files := map[string][]locatedIssue{}
+	for _, issue := range report.Issues {
+		li := locatedIssue{Issue: issue}
+		source := ""
+		if issue.Entry != nil {
+			source = issue.Entry.Source
+			if issue.Path != nil {
+				line, column, err := evalJSONPath(issue.Entry, issue.Path)
+				if err != nil {
+					return errors.Wrap(err, errEvaluateJSONPath)
+				}
+				li.line, li.column = line, column
+			}
+		}
+		files[source] = append(files[source], li)
+	}
+
+	sources := make([]string, 0, len(files))
+	for source := range files {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		issues := files[source]
+		sort.SliceStable(issues, func(i, j int) bool {
+			if issues[i].line != issues[j].line {
+				return issues[i].line < issues[j].line
+			}
+			if issues[i].column != issues[j].column {
+				return issues[i].column < issues[j].column
+			}
+			return issues[i].RuleName < issues[j].RuleName
+		})
+		header := source
+		if header == "" {
+			header = packageIssuesHeader
+		}
+		fmt.Fprintln(p.out, p.render(color.OpUnderscore, header))
+		for _, issue := range issues {
+			if err := p.printIssue(issue); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(p.out, "")
+	}
+	return p.printSummary(report)
+}
+
+func (p *CodeFramePrinter) printIssue(issue locatedIssue) error {
+	position := ""
+	if issue.line > 0 {
+		position = fmt.Sprintf("%d:%d", issue.line, issue.column)
+	}
+	c := severityColor(issue.Severity)
+	fmt.Fprintf(p.out, "  %s  %s  %s  %s\n",
+		p.render(color.FgGray, fmt.Sprintf("%-7s", position)),
+		p.render(c, fmt.Sprintf("%-7s", severityName(issue.Severity))),
+		issue.Description,
+		p.render(color.FgGray, issue.RuleName))
+	if issue.Entry != nil && issue.line > 0 {
+		p.printCodeFrame(issue.Entry.Raw, issue.line, issue.column, issue.PathValue)
+	}
+	if issue.Schema != "" {
+		fmt.Fprintln(p.out, p.render(color.FgGray, fmt.Sprintf("      validated against %s", issue.Schema)))
+	}
+	for _, r := range issue.Related {
+		if r.Entry == nil {
+			continue
+		}
+		line, column, err := evalJSONPath(r.Entry, r.Path)
+		if err != nil {
+			return errors.Wrap(err, errEvaluateJSONPath)
+		}
+		fmt.Fprintln(p.out, p.render(color.FgBlue, fmt.Sprintf("      also affects %s:%d:%d %s", r.Entry.Source, line, column, r.Path.String())))
+	}
+	for _, suggestion := range issue.Suggestions {
+		fmt.Fprintln(p.out, p.render(color.FgYellow, fmt.Sprintf("      did you mean: %s", suggestion)))
+	}
+	for _, f := range issue.Fixes {
+		fmt.Fprintln(p.out, p.render(color.FgGreen, fmt.Sprintf("      fix: %s", f.Description)))
+	}
+	return nil
+}
+
+// printCodeFrame prints the lines of raw around line with a caret under
+// column. The caret spans value if the line contains it at column.
+func (p *CodeFramePrinter) printCodeFrame(raw string, line, column int, value string) {
+	lines := strings.Split(strings.TrimSuffix(raw, "\n"), "\n")
+	if line > len(lines) {
+		return
+	}
+	first := line - codeFrameContextLines
+	if first < 1 {
+		first = 1
+	}
+	last := line + codeFrameContextLines
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(fmt.Sprint(last))
+
+	fmt.Fprintln(p.out, "")
+	for l := first; l <= last; l++ {
+		marker := " "
+		if l == line {
+			marker = p.render(color.FgRed, ">")
+		}
+		gutter := p.render(color.FgGray, fmt.Sprintf("%*d |", width, l))
+		fmt.Fprintf(p.out, "    %s %s %s\n", marker, gutter, lines[l-1])
+		if l == line {
+			gutter := p.render(color.FgGray, fmt.Sprintf("%*s |", width, ""))
+			fmt.Fprintf(p.out, "      %s %s\n", gutter, p.render(color.FgRed, caret(lines[l-1], column, value)))
+		}
+	}
+	fmt.Fprintln(p.out, "")
+}
+
+// caret returns the caret line for the value at column (1-based, in runes) of
+// line. Tabs before column are kept, so the caret is aligned with them.
+func caret(line string, column int, value string) string {
+	runes := []rune(line)
+	b := strings.Builder{}
+	for i := 0; i < column-1 && i < len(runes); i++ {
+		if runes[i] == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	length := 1
+	if column-1 <= len(runes) && value != "" && strings.HasPrefix(string(runes[column-1:]), value) {
+		length = len([]rune(value))
+	}
+	b.WriteString(strings.Repeat("^", length))
+	return b.String()
+}
+
+// printSummary prints the number of issues per rule and severity.
+func (p *CodeFramePrinter) printSummary(report lint.LinterReport) error {
+	if len(report.Issues) == 0 {
+		return nil
+	}
+	counts := map[string]map[lint.Severity]int{}
+	for _, issue := range report.Issues {
+		if counts[issue.RuleName] == nil {
+			counts[issue.RuleName] = map[lint.Severity]int{}
+		}
+		severity := issue.Severity
+		if severity == "" {
+			severity = lint.SeverityError
+		}
+		counts[issue.RuleName][severity]++
+	}
+	rules := make([]string, 0, len(counts))
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	// Colors would break the alignment of tabwriter.
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "rule\terrors\twarnings\tinfos")
+	totals := map[lint.Severity]int{}
+	for _, rule := range rules {
+		fmt.Fprint(w, rule)
+		for _, sev := range lint.Severities() {
+			fmt.Fprintf(w, "\t%d", counts[rule][sev])
+			totals[sev] += counts[rule][sev]
+		}
+		fmt.Fprintln(w, "")
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\t%d\n", totals[lint.SeverityError], totals[lint.SeverityWarning], totals[lint.SeverityInfo])
+	return w.Flush()
+}
+
+// render s with c if colors are enabled.
+func (p *CodeFramePrinter) render(c color.Color, s string) string {
+	if !p.colors {
+		return s
+	}
+	return c.Render(s)
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/gookit/color"
 	"github.com/pkg/errors"
-	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
 
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
@@ -16,7 +15,6 @@ import (
 const (
 	errEvaluateJSONPath = "failed to evaluate JSON Path"
 	errGetYamlNode      = "failed to parse yaml"
-	errParsePath        = "failed to parse JSON path"
 	errFindPath         = "failed to evaluate JSON path"
 )
 
@@ -43,7 +41,8 @@ func (p *TextPrinter) PrintReport(report lint.LinterReport) error {
 }
 
 func (p *TextPrinter) printIssue(issue lint.Issue) error {
-	fmt.Fprintf(p.out, "[%s] %s\n", color.Red.Render(issue.RuleName), issue.Description)
+	c := severityColor(issue.Severity)
+	fmt.Fprintf(p.out, "[%s] %s: %s\n", c.Render(issue.RuleName), c.Render(severityName(issue.Severity)), issue.Description)
 	if issue.Entry == nil {
 		return nil
 	}
@@ -57,25 +56,71 @@ func (p *TextPrinter) printIssue(issue lint.Issue) error {
 	}
 	fmt.Fprintf(p.out, "  %s: %s\n", issue.Path.String(), issue.PathValue)
 	fmt.Fprintln(p.out, color.Blue.Render(fmt.Sprintf("  in %s:%d:%d", issue.Entry.Source, line, column)))
+	if issue.Schema != "" {
+		fmt.Fprintln(p.out, color.Gray.Render(fmt.Sprintf("  validated against %s", issue.Schema)))
+	}
+	if err := p.printRelated(issue.Related); err != nil {
+		return err
+	}
+	for _, suggestion := range issue.Suggestions {
+		fmt.Fprintln(p.out, color.Yellow.Render(fmt.Sprintf("  did you mean: %s", suggestion)))
+	}
+	for _, f := range issue.Fixes {
+		fmt.Fprintln(p.out, color.Green.Render(fmt.Sprintf("  fix: %s", f.Description)))
+	}
 	return nil
 }
 
-func evalJSONPath(e *xpkg.PackageEntry, path jsonpath.JSONPath) (line, column int, err error) {
-	yamlPath, err := yamlpath.NewPath(path.String())
-	if err != nil {
-		return 0, 0, errors.Wrap(err, errParsePath)
+// printRelated prints the locations that are affected by an issue as well.
+func (p *TextPrinter) printRelated(related []lint.Location) error {
+	if len(related) == 0 {
+		return nil
+	}
+	fmt.Fprintln(p.out, "  also affects:")
+	for _, r := range related {
+		if r.Entry == nil {
+			continue
+		}
+		line, column, err := evalJSONPath(r.Entry, r.Path)
+		if err != nil {
+			return errors.Wrap(err, errEvaluateJSONPath)
+		}
+		fmt.Fprintf(p.out, "    %s\n", r.Path.String())
+		fmt.Fprintln(p.out, color.Blue.Render(fmt.Sprintf("    in %s:%d:%d", r.Entry.Source, line, column)))
 	}
+	return nil
+}
+
+func severityColor(severity lint.Severity) color.Color {
+	switch severity {
+	case lint.SeverityWarning:
+		return color.Yellow
+	case lint.SeverityInfo:
+		return color.Cyan
+	}
+	return color.Red
+}
+
+// severityName returns the name of severity. Issues without severity are
+// errors.
+func severityName(severity lint.Severity) string {
+	if severity == "" {
+		return string(lint.SeverityError)
+	}
+	return string(severity)
+}
+
+func evalJSONPath(e *xpkg.PackageEntry, path jsonpath.JSONPath) (line, column int, err error) {
 	node, err := e.GetYamlNode()
 	if err != nil {
 		return 0, 0, errors.Wrap(err, errGetYamlNode)
 	}
-	pathNodes, err := yamlPath.Find(node)
+	pathNode, err := jsonpath.Find(node, path)
 	if err != nil {
 		return 0, 0, errors.Wrap(err, errFindPath)
 	}
-	if len(pathNodes) == 0 {
+	if pathNode == nil {
 		return 0, 0, nil
 	}
-	pathNode := pathNodes[0]
 	return pathNode.Line, pathNode.Column, nil
 }
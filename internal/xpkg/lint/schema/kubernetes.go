@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+// BundledKubernetesVersion is the version of Kubernetes whose API schemas are
+// bundled with the linter. The schemas are derived from the Go types of
+// k8s.io/api.
+const BundledKubernetesVersion = "1.24"
+
+// RegisterBundledKubernetesSchemas registers the schemas of all built-in
+// kinds of Kubernetes BundledKubernetesVersion.
+func (s *SchemaStore) RegisterBundledKubernetesSchemas() {
+	g := &typeSchemaGenerator{cache: map[reflect.Type]extv1.JSONSchemaProps{}}
+	for gvk, t := range scheme.Scheme.AllKnownTypes() {
+		if !isKubernetesResource(gvk, t) {
+			continue
+		}
+		props := g.schemaFor(t, map[reflect.Type]bool{})
+		s.RegisterSchema(gvk, &props, "Kubernetes "+BundledKubernetesVersion)
+	}
+}
+
+// RegisterSchema registers props defined by source as schema of gvk, for
+// example of an OpenAPI document. Replaces a previously registered schema of
+// gvk unless it is defined by a package.
+func (s *SchemaStore) RegisterSchema(gvk schema.GroupVersionKind, props *extv1.JSONSchemaProps, source string) {
+	version := &extv1.CustomResourceDefinitionVersion{
+		Name:   gvk.Version,
+		Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: props},
+	}
+	addMetaDataToSchema(version)
+	origin := lint.SchemaOrigin{Package: source, Kind: originKindSchema, Name: gvk.GroupKind().String()}
+	s.schemas[gvk] = storedSchema{version: version, origin: origin}
+	s.register(gvk, version, origin)
+}
+
+// isKubernetesResource determines if t of gvk is a resource with metadata.
+// Excludes lists and the option types registered for each group version.
+func isKubernetesResource(gvk schema.GroupVersionKind, t reflect.Type) bool {
+	if strings.HasSuffix(gvk.Kind, "List") || t.Kind() != reflect.Struct {
+		return false
+	}
+	f, ok := t.FieldByName("ObjectMeta")
+	return ok && f.Type == reflect.TypeOf(metav1.ObjectMeta{})
+}
+
+var (
+	// Types that can hold arbitrary objects.
+	preserveUnknownTypes = map[reflect.Type]bool{
+		reflect.TypeOf(runtime.RawExtension{}): true,
+		reflect.TypeOf(runtime.Unknown{}):      true,
+		reflect.TypeOf(metav1.FieldsV1{}):      true,
+	}
+
+	byteSliceType = reflect.TypeOf([]byte{})
+)
+
+// openAPISchemaType is implemented by types with a custom JSON encoding.
+type openAPISchemaType interface {
+	OpenAPISchemaType() []string
+	OpenAPISchemaFormat() string
+}
+
+// openAPIV3OneOfTypes is implemented by types that are encoded as one of
+// multiple types.
+type openAPIV3OneOfTypes interface {
+	OpenAPIV3OneOfTypes() []string
+}
+
+// typeSchemaGenerator derives schemas from Go types by their JSON encoding.
+type typeSchemaGenerator struct {
+	cache map[reflect.Type]extv1.JSONSchemaProps
+}
+
+// schemaFor returns the schema of t. parents are the types that are currently
+// generated to detect recursive types, which allow unknown fields.
+func (g *typeSchemaGenerator) schemaFor(t reflect.Type, parents map[reflect.Type]bool) extv1.JSONSchemaProps {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if props, exists := g.cache[t]; exists {
+		return props
+	}
+	if parents[t] || preserveUnknownTypes[t] {
+		return extv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: pointer.Bool(true)}
+	}
+	if v, ok := reflect.Zero(t).Interface().(openAPIV3OneOfTypes); ok && len(v.OpenAPIV3OneOfTypes()) > 1 {
+		return extv1.JSONSchemaProps{XIntOrString: true}
+	}
+	if v, ok := reflect.Zero(t).Interface().(openAPISchemaType); ok && len(v.OpenAPISchemaType()) == 1 {
+		return extv1.JSONSchemaProps{Type: v.OpenAPISchemaType()[0], Format: v.OpenAPISchemaFormat()}
+	}
+	if t == byteSliceType {
+		return extv1.JSONSchemaProps{Type: "string", Format: "byte"}
+	}
+
+	var props extv1.JSONSchemaProps
+	switch t.Kind() {
+	case reflect.Bool:
+		props = extv1.JSONSchemaProps{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		props = extv1.JSONSchemaProps{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		props = extv1.JSONSchemaProps{Type: "number"}
+	case reflect.String:
+		props = extv1.JSONSchemaProps{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		items := g.schemaFor(t.Elem(), parents)
+		props = extv1.JSONSchemaProps{Type: "array", Items: &extv1.JSONSchemaPropsOrArray{Schema: &items}}
+	case reflect.Map:
+		values := g.schemaFor(t.Elem(), parents)
+		props = extv1.JSONSchemaProps{Type: "object", AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Allows: true, Schema: &values}}
+	case reflect.Struct:
+		parents[t] = true
+		props = extv1.JSONSchemaProps{Type: "object", Properties: map[string]extv1.JSONSchemaProps{}}
+		g.addStructFields(&props, t, parents)
+		delete(parents, t)
+	default:
+		props = extv1.JSONSchemaProps{XPreserveUnknownFields: pointer.Bool(true)}
+	}
+	g.cache[t] = props
+	return props
+}
+
+// addStructFields adds the JSON encoded fields of the struct t to props.
+// Fields of inlined structs are added to props directly.
+func (g *typeSchemaGenerator) addStructFields(props *extv1.JSONSchemaProps, t reflect.Type, parents map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// Unexported fields are not encoded.
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if (f.Anonymous && name == "") || strings.Contains(opts, "inline") {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addStructFields(props, ft, parents)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props.Properties[name] = g.schemaFor(f.Type, parents)
+	}
+}
@@ -0,0 +1,186 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	errParseOpenAPI       = "failed to parse OpenAPI document"
+	errNoOpenAPISchemas   = "OpenAPI document contains neither components.schemas nor definitions"
+	errFmtConvertSchema   = "failed to convert schema %s"
+	errFmtUnresolvableRef = "cannot resolve reference %s"
+
+	// Extension that lists the GVKs of a schema of a Kubernetes OpenAPI
+	// document.
+	gvkExtension = "x-kubernetes-group-version-kind"
+)
+
+// openAPIDocument is an OpenAPI v3 or v2 (swagger) document as published by
+// the Kubernetes API server.
+type openAPIDocument struct {
+	Components struct {
+		Schemas map[string]map[string]interface{} `json:"schemas"`
+	} `json:"components"`
+	Definitions map[string]map[string]interface{} `json:"definitions"`
+}
+
+// RegisterOpenAPISchemas registers the schemas of all kinds of the Kubernetes
+// OpenAPI v3 or v2 document raw, for example the output of
+// `kubectl get --raw /openapi/v3/api/v1`. Schemas are identified by their
+// x-kubernetes-group-version-kind extension. Returns the number of registered
+// kinds. source names the document in the origins of the schemas.
+func (s *SchemaStore) RegisterOpenAPISchemas(raw []byte, source string) (int, error) {
+	doc := openAPIDocument{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return 0, errors.Wrap(err, errParseOpenAPI)
+	}
+	r := &refResolver{schemas: doc.Components.Schemas, prefix: "#/components/schemas/"}
+	if len(r.schemas) == 0 {
+		r = &refResolver{schemas: doc.Definitions, prefix: "#/definitions/"}
+	}
+	if len(r.schemas) == 0 {
+		return 0, errors.New(errNoOpenAPISchemas)
+	}
+
+	count := 0
+	for name, def := range r.schemas {
+		gvks := extensionGVKs(def)
+		if len(gvks) == 0 {
+			continue
+		}
+		resolved, err := r.resolve(def, map[string]bool{name: true})
+		if err != nil {
+			return 0, errors.Wrapf(err, errFmtConvertSchema, name)
+		}
+		data, err := json.Marshal(resolved)
+		if err != nil {
+			return 0, errors.Wrapf(err, errFmtConvertSchema, name)
+		}
+		for _, gvk := range gvks {
+			props := &extv1.JSONSchemaProps{}
+			if err := json.Unmarshal(data, props); err != nil {
+				return 0, errors.Wrapf(err, errFmtConvertSchema, name)
+			}
+			s.RegisterSchema(gvk, props, source)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// extensionGVKs returns the GVKs of the x-kubernetes-group-version-kind
+// extension of def. Excludes lists, which are not composed.
+func extensionGVKs(def map[string]interface{}) []schema.GroupVersionKind {
+	list, ok := def[gvkExtension].([]interface{})
+	if !ok {
+		return nil
+	}
+	gvks := []schema.GroupVersionKind{}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		gvk := schema.GroupVersionKind{}
+		gvk.Group, _ = m["group"].(string)
+		gvk.Version, _ = m["version"].(string)
+		gvk.Kind, _ = m["kind"].(string)
+		if gvk.Kind == "" || strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+		gvks = append(gvks, gvk)
+	}
+	return gvks
+}
+
+// refResolver inlines the $ref references of the schemas of an OpenAPI
+// document.
+type refResolver struct {
+	schemas map[string]map[string]interface{}
+	prefix  string
+}
+
+// resolve returns a copy of v with all references replaced by the referenced
+// schemas. parents are the schemas that are currently being resolved.
+// Recursive references are replaced by a schema that allows any object.
+func (r *refResolver) resolve(v interface{}, parents map[string]bool) (interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := t["$ref"].(string); ok {
+			return r.resolveRef(ref, parents)
+		}
+		res := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			if k == gvkExtension {
+				continue
+			}
+			resolved, err := r.resolve(val, parents)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = resolved
+		}
+		return normalizeSchema(res), nil
+	case []interface{}:
+		res := make([]interface{}, len(t))
+		for i, val := range t {
+			resolved, err := r.resolve(val, parents)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = resolved
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+func (r *refResolver) resolveRef(ref string, parents map[string]bool) (interface{}, error) {
+	name := strings.TrimPrefix(ref, r.prefix)
+	def, exists := r.schemas[name]
+	if name == ref || !exists {
+		return nil, errors.Errorf(errFmtUnresolvableRef, ref)
+	}
+	if parents[name] {
+		return map[string]interface{}{
+			"type":                                 "object",
+			"x-kubernetes-preserve-unknown-fields": true,
+		}, nil
+	}
+	parents[name] = true
+	defer delete(parents, name)
+	return r.resolve(def, parents)
+}
+
+// normalizeSchema converts the constructs of Kubernetes OpenAPI documents that
+// JSONSchemaProps does not support. References with siblings are wrapped in
+// a single allOf by OpenAPI v3 documents, which is collapsed into the schema.
+// OpenAPI v2 documents mark int-or-string values by format.
+func normalizeSchema(s map[string]interface{}) map[string]interface{} {
+	if allOf, ok := s["allOf"].([]interface{}); ok && len(allOf) == 1 {
+		if inner, ok := allOf[0].(map[string]interface{}); ok {
+			delete(s, "allOf")
+			for k, v := range inner {
+				if _, exists := s[k]; !exists {
+					s[k] = v
+				}
+			}
+		}
+	}
+	if s["format"] == "int-or-string" {
+		delete(s, "type")
+		delete(s, "format")
+		s["x-kubernetes-int-or-string"] = true
+	}
+	// Defaults of referenced schemas are not valid JSONSchemaProps defaults
+	// for every type and are not needed for validation.
+	delete(s, "default")
+	return s
+}
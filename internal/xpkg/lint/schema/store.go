@@ -1,69 +1,220 @@
 package schema
 
 import (
+	"fmt"
+	"reflect"
+
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
-	"github.com/pkg/errors"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+const errFmtSkippedDefinition = "skipped schemas of %s in %s: %v"
+
+// Kinds of schema definitions.
 const (
-	errBuildCompositeCRD = "failed to build composite CRD"
-	errBuildClaimCRD     = "failed to build claim CRD"
+	originKindCRD    = "CRD"
+	originKindXRD    = "XRD"
+	originKindSchema = "schema"
 )
 
 type SchemaStore struct {
-	versions map[schema.GroupVersionKind]*extv1.CustomResourceDefinitionVersion
+	versions    map[schema.GroupVersionKind]*extv1.CustomResourceDefinitionVersion
+	origins     map[schema.GroupVersionKind]lint.SchemaOrigin
+	definitions map[schema.GroupKind][]lint.SchemaOrigin
+	functions   map[string]struct{}
+	environment *extv1.JSONSchemaProps
+	conflicts   []lint.SchemaConflict
+
+	// schemas are the schemas that are not defined by a manifest and
+	// packages the registered packages in order, so the store can be
+	// rebuilt when a package changes. See UpdatePackage.
+	schemas  map[schema.GroupVersionKind]storedSchema
+	packages []*xpkg.Package
+}
+
+type storedSchema struct {
+	version *extv1.CustomResourceDefinitionVersion
+	origin  lint.SchemaOrigin
 }
 
 func NewSchemaStore() *SchemaStore {
-	return &SchemaStore{
-		versions: map[schema.GroupVersionKind]*extv1.CustomResourceDefinitionVersion{},
+	s := &SchemaStore{schemas: map[schema.GroupVersionKind]storedSchema{}}
+	s.reset()
+	return s
+}
+
+// reset removes all registered schemas, definitions, conflicts and functions.
+func (s *SchemaStore) reset() {
+	s.versions = map[schema.GroupVersionKind]*extv1.CustomResourceDefinitionVersion{}
+	s.origins = map[schema.GroupVersionKind]lint.SchemaOrigin{}
+	s.definitions = map[schema.GroupKind][]lint.SchemaOrigin{}
+	s.functions = map[string]struct{}{}
+	s.conflicts = nil
+}
+
+// SkippedDefinition is a CRD or XRD whose schemas could not be registered.
+type SkippedDefinition struct {
+	// Entry that defines the schemas.
+	Entry *xpkg.PackageEntry
+
+	// Err is the reason the schemas could not be registered.
+	Err error
+}
+
+func (d SkippedDefinition) Error() string {
+	return fmt.Sprintf(errFmtSkippedDefinition, d.Entry.Object.GetName(), d.Entry.Source, d.Err)
+}
+
+func (d SkippedDefinition) Unwrap() error {
+	return d.Err
+}
+
+// RegisterPackage registers the CRDs, XRDs and Functions of pkg. A kind that
+// is already defined by another manifest keeps its schema and a conflict is
+// recorded if the schemas differ, so the first registered package wins.
+// Manifests that are registered again, for example after they changed,
+// replace their previous schemas. CRDs and XRDs that cannot be converted are
+// skipped, so the rest of the package can still be linted, and returned.
+func (s *SchemaStore) RegisterPackage(pkg *xpkg.Package) []SkippedDefinition {
+	s.packages = append(s.packages, pkg)
+	return s.registerPackage(pkg)
+}
+
+// UpdatePackage registers the schemas of all registered packages again after
+// the entries of pkg changed. Schemas of removed or changed manifests are
+// unregistered and definitions they shadowed take effect again. pkg is
+// registered if it was not registered before. Returns the skipped definitions
+// of pkg.
+func (s *SchemaStore) UpdatePackage(pkg *xpkg.Package) []SkippedDefinition {
+	registered := false
+	for _, p := range s.packages {
+		registered = registered || p == pkg
+	}
+	if !registered {
+		s.packages = append(s.packages, pkg)
+	}
+	s.reset()
+	for gvk, stored := range s.schemas {
+		s.versions[gvk] = stored.version
+		s.origins[gvk] = stored.origin
+	}
+	var skipped []SkippedDefinition
+	for _, p := range s.packages {
+		pkgSkipped := s.registerPackage(p)
+		if p == pkg {
+			skipped = pkgSkipped
+		}
 	}
+	return skipped
 }
 
-func (s *SchemaStore) RegisterPackage(pkg *xpkg.Package) error {
-	for _, e := range pkg.Entries {
+func (s *SchemaStore) registerPackage(pkg *xpkg.Package) []SkippedDefinition {
+	pkgName := pkg.Name
+	if pkgName == "" {
+		pkgName = pkg.Source
+	}
+	var skipped []SkippedDefinition
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		origin := lint.SchemaOrigin{Package: pkgName, Entry: e, Name: e.Object.GetName()}
 		switch {
 		case e.IsCRD():
 			crd, err := e.AsCRD()
 			if err != nil {
-				return err
+				skipped = append(skipped, SkippedDefinition{Entry: e, Err: err})
+				continue
 			}
-			s.registerCRD(crd)
+			origin.Kind = originKindCRD
+			s.registerCRD(crd, origin)
 		case e.IsXRD():
 			xrd, err := e.AsXRD()
 			if err != nil {
-				return err
+				skipped = append(skipped, SkippedDefinition{Entry: e, Err: err})
+				continue
 			}
-			comp, err := ForCompositeResource(xrd)
-			if err != nil {
-				return errors.Wrap(err, errBuildCompositeCRD)
+			origin.Kind = originKindXRD
+			if comp, err := ForCompositeResource(xrd); err == nil {
+				s.registerCRD(comp, origin)
+			} else {
+				skipped = append(skipped, SkippedDefinition{Entry: e, Err: err})
 			}
-			s.registerCRD(comp)
-			if xrd.Spec.ClaimNames != nil {
-				claim, err := ForCompositeResourceClaim(xrd)
-				if err != nil {
-					return errors.Wrap(err, errBuildClaimCRD)
-				}
-				s.registerCRD(claim)
+			if xrd.Spec.ClaimNames == nil {
+				continue
 			}
+			claim, err := ForCompositeResourceClaim(xrd)
+			if err != nil {
+				skipped = append(skipped, SkippedDefinition{Entry: e, Err: err})
+				continue
+			}
+			s.registerCRD(claim, origin)
+		case e.IsFunction():
+			s.functions[e.Object.GetName()] = struct{}{}
 		}
 	}
-	return nil
+	return skipped
 }
 
-func (s *SchemaStore) registerCRD(crd *extv1.CustomResourceDefinition) {
+func (s *SchemaStore) registerCRD(crd *extv1.CustomResourceDefinition, origin lint.SchemaOrigin) {
 	gk := schema.GroupKind{
 		Group: crd.Spec.Group,
 		Kind:  crd.Spec.Names.Kind,
 	}
+	s.addDefinition(gk, origin)
 	for _, v := range crd.Spec.Versions {
 		version := v
 		gvk := gk.WithVersion(version.Name)
 		addMetaDataToSchema(&version)
-		s.versions[gvk] = &version
+		s.register(gvk, &version, origin)
+	}
+}
+
+// register version as schema of gvk unless gvk is already defined by another
+// manifest. Schemas that are not defined by a manifest never replace schemas
+// defined by one and are always replaced.
+func (s *SchemaStore) register(gvk schema.GroupVersionKind, version *extv1.CustomResourceDefinitionVersion, origin lint.SchemaOrigin) {
+	existing, exists := s.origins[gvk]
+	if exists && existing.Entry != nil {
+		if origin.Entry == nil {
+			return
+		}
+		if existing.Entry.Source != origin.Entry.Source {
+			if !reflect.DeepEqual(s.versions[gvk].Schema, version.Schema) {
+				s.conflicts = append(s.conflicts, lint.SchemaConflict{GVK: gvk, Used: existing, Ignored: origin})
+			}
+			return
+		}
+		// The manifest changed, so its conflicts are determined again.
+		s.removeConflicts(gvk, origin.Entry.Source)
+	}
+	s.versions[gvk] = version
+	s.origins[gvk] = origin
+}
+
+// addDefinition records that origin defines gk. Replaces the previous
+// definition of the same manifest.
+func (s *SchemaStore) addDefinition(gk schema.GroupKind, origin lint.SchemaOrigin) {
+	defs := s.definitions[gk][:0]
+	for _, d := range s.definitions[gk] {
+		if d.Entry.Source != origin.Entry.Source || d.Name != origin.Name {
+			defs = append(defs, d)
+		}
 	}
+	s.definitions[gk] = append(defs, origin)
+}
+
+// removeConflicts removes the conflicts of gvk that involve the manifest
+// source.
+func (s *SchemaStore) removeConflicts(gvk schema.GroupVersionKind, source string) {
+	kept := s.conflicts[:0]
+	for _, c := range s.conflicts {
+		if c.GVK == gvk && (c.Used.Entry.Source == source || c.Ignored.Entry.Source == source) {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	s.conflicts = kept
 }
 
 func addMetaDataToSchema(crdv *extv1.CustomResourceDefinitionVersion) {
@@ -102,6 +253,9 @@ func addMetaDataToSchema(crdv *extv1.CustomResourceDefinitionVersion) {
 			Properties: map[string]extv1.JSONSchemaProps{},
 		}
 	}
+	if crdv.Schema.OpenAPIV3Schema.Properties == nil {
+		crdv.Schema.OpenAPIV3Schema.Properties = map[string]extv1.JSONSchemaProps{}
+	}
 	if _, exists := crdv.Schema.OpenAPIV3Schema.Properties["metadata"]; !exists {
 		crdv.Schema.OpenAPIV3Schema.Properties["metadata"] = extv1.JSONSchemaProps{
 			Type:       "object",
@@ -122,6 +276,28 @@ func addMetaDataToSchema(crdv *extv1.CustomResourceDefinitionVersion) {
 	}
 }
 
+// GetSchemaOrigin returns where the schema of gvk is defined or nil if it is
+// unknown.
+func (s *SchemaStore) GetSchemaOrigin(gvk schema.GroupVersionKind) *lint.SchemaOrigin {
+	origin, exists := s.origins[gvk]
+	if !exists {
+		return nil
+	}
+	return &origin
+}
+
+// GetKindDefinitions returns all manifests that define gk in the order they
+// were registered, regardless of their versions and schemas.
+func (s *SchemaStore) GetKindDefinitions(gk schema.GroupKind) []lint.SchemaOrigin {
+	return append([]lint.SchemaOrigin{}, s.definitions[gk]...)
+}
+
+// GetSchemaConflicts returns the kinds that are defined by multiple manifests
+// with different schemas.
+func (s *SchemaStore) GetSchemaConflicts() []lint.SchemaConflict {
+	return append([]lint.SchemaConflict{}, s.conflicts...)
+}
+
 func (s *SchemaStore) GetCRDSchema(gvk schema.GroupVersionKind) *extv1.CustomResourceDefinitionVersion {
 	version, exists := s.versions[gvk]
 	if !exists {
@@ -129,3 +305,22 @@ func (s *SchemaStore) GetCRDSchema(gvk schema.GroupVersionKind) *extv1.CustomRes
 	}
 	return version
 }
+
+// HasFunction determines if a Function or Function package with the given
+// name has been registered.
+func (s *SchemaStore) HasFunction(name string) bool {
+	_, exists := s.functions[name]
+	return exists
+}
+
+// RegisterEnvironmentSchema registers the schema of the data of composition
+// environments.
+func (s *SchemaStore) RegisterEnvironmentSchema(props *extv1.JSONSchemaProps) {
+	s.environment = props
+}
+
+// GetEnvironmentSchema returns the schema of composition environments or nil
+// if none has been registered.
+func (s *SchemaStore) GetEnvironmentSchema() *extv1.JSONSchemaProps {
+	return s.environment
+}
@@ -0,0 +1,217 @@
+package schema
+
+import (
+	"testing"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+)
+
+const configMapCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: configmaps
+spec:
+  group: ""
+  names:
+    kind: ConfigMap
+    plural: configmaps
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+
+var configMapGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+// newPackage returns a package with the single entry manifest read from
+// source.
+func newPackage(t *testing.T, source, manifest string) *xpkg.Package {
+	t.Helper()
+	o := unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(manifest), &o); err != nil {
+		t.Fatalf("yaml.Unmarshal(...): unexpected error: %v", err)
+	}
+	return &xpkg.Package{Source: "pkg", Entries: []xpkg.PackageEntry{{Object: o, Source: source, Raw: manifest}}}
+}
+
+func TestRegisterSchema(t *testing.T) {
+	cases := map[string]struct {
+		reason     string
+		register   func(t *testing.T, s *SchemaStore)
+		wantOrigin string
+	}{
+		"PackageAfterSchema": {
+			reason: "Schemas defined by a package replace other schemas.",
+			register: func(t *testing.T, s *SchemaStore) {
+				s.RegisterSchema(configMapGVK, &extv1.JSONSchemaProps{}, "openapi.json")
+				if skipped := s.RegisterPackage(newPackage(t, "crd.yaml", configMapCRD)); len(skipped) > 0 {
+					t.Fatalf("RegisterPackage(...): unexpected skipped definitions: %v", skipped)
+				}
+			},
+			wantOrigin: "pkg CRD configmaps",
+		},
+		"SchemaAfterPackage": {
+			reason: "Schemas defined by a package are not replaced by other schemas.",
+			register: func(t *testing.T, s *SchemaStore) {
+				if skipped := s.RegisterPackage(newPackage(t, "crd.yaml", configMapCRD)); len(skipped) > 0 {
+					t.Fatalf("RegisterPackage(...): unexpected skipped definitions: %v", skipped)
+				}
+				s.RegisterSchema(configMapGVK, &extv1.JSONSchemaProps{}, "openapi.json")
+			},
+			wantOrigin: "pkg CRD configmaps",
+		},
+		"SchemaAfterSchema": {
+			reason: "Schemas that are not defined by a package replace each other.",
+			register: func(t *testing.T, s *SchemaStore) {
+				s.RegisterBundledKubernetesSchemas()
+				s.RegisterSchema(configMapGVK, &extv1.JSONSchemaProps{}, "openapi.json")
+			},
+			wantOrigin: "openapi.json schema ConfigMap",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewSchemaStore()
+			tc.register(t, s)
+			got := s.GetSchemaOrigin(configMapGVK)
+			if got == nil {
+				t.Fatalf("\n%s\nGetSchemaOrigin(...): want %s, got nil", tc.reason, tc.wantOrigin)
+			}
+			if got.String() != tc.wantOrigin {
+				t.Errorf("\n%s\nGetSchemaOrigin(...): want %s, got %s", tc.reason, tc.wantOrigin, got.String())
+			}
+			if len(s.GetSchemaConflicts()) != 0 {
+				t.Errorf("\n%s\nGetSchemaConflicts(): want none, got %v", tc.reason, s.GetSchemaConflicts())
+			}
+		})
+	}
+}
+
+func TestRegisterPackageSkipped(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		manifest    string
+		wantSkipped bool
+	}{
+		"ValidCRD": {
+			reason:   "Valid CRDs are registered.",
+			manifest: configMapCRD,
+		},
+		"InvalidCRD": {
+			reason: "CRDs that cannot be converted are skipped.",
+			manifest: `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: invalid
+spec: invalid
+`,
+			wantSkipped: true,
+		},
+		"InvalidClaimNames": {
+			reason: "XRDs whose claim CRD cannot be built are skipped.",
+			manifest: `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xexamples.example.org
+spec:
+  group: example.org
+  names:
+    kind: XExample
+    plural: xexamples
+  claimNames:
+    kind: XExample
+    plural: xexamples
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+`,
+			wantSkipped: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := newPackage(t, "def.yaml", tc.manifest)
+			skipped := NewSchemaStore().RegisterPackage(pkg)
+			if got := len(skipped) > 0; got != tc.wantSkipped {
+				t.Fatalf("\n%s\nRegisterPackage(...): want skipped %t, got %v", tc.reason, tc.wantSkipped, skipped)
+			}
+			for _, s := range skipped {
+				if s.Entry != &pkg.Entries[0] {
+					t.Errorf("\n%s\nRegisterPackage(...): want skipped entry %s, got %v", tc.reason, pkg.Entries[0].Source, s.Entry)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdatePackage(t *testing.T) {
+	otherCRD := configMapCRD + `        properties:
+          data:
+            type: object
+`
+	cases := map[string]struct {
+		reason     string
+		register   func(t *testing.T, s *SchemaStore) *xpkg.Package
+		wantOrigin string
+	}{
+		"ShadowedDefinition": {
+			reason: "Definitions shadowed by a removed manifest take effect again.",
+			register: func(t *testing.T, s *SchemaStore) *xpkg.Package {
+				pkg := newPackage(t, "a.yaml", configMapCRD)
+				pkg.Entries = append(pkg.Entries, newPackage(t, "b.yaml", otherCRD).Entries...)
+				s.RegisterPackage(pkg)
+				return pkg
+			},
+			wantOrigin: "pkg CRD configmaps b.yaml",
+		},
+		"ShadowedSchema": {
+			reason: "Schemas that are not defined by a package take effect again when the manifest that replaced them is removed.",
+			register: func(t *testing.T, s *SchemaStore) *xpkg.Package {
+				s.RegisterSchema(configMapGVK, &extv1.JSONSchemaProps{}, "openapi.json")
+				pkg := newPackage(t, "a.yaml", configMapCRD)
+				s.RegisterPackage(pkg)
+				return pkg
+			},
+			wantOrigin: "openapi.json schema ConfigMap ",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewSchemaStore()
+			pkg := tc.register(t, s)
+			pkg.RemoveEntry("a.yaml")
+			if skipped := s.UpdatePackage(pkg); len(skipped) > 0 {
+				t.Fatalf("\n%s\nUpdatePackage(...): unexpected skipped definitions: %v", tc.reason, skipped)
+			}
+			origin := s.GetSchemaOrigin(configMapGVK)
+			if origin == nil {
+				t.Fatalf("\n%s\nGetSchemaOrigin(...): want %s, got nil", tc.reason, tc.wantOrigin)
+			}
+			source := ""
+			if origin.Entry != nil {
+				source = origin.Entry.Source
+			}
+			if got := origin.String() + " " + source; got != tc.wantOrigin {
+				t.Errorf("\n%s\nGetSchemaOrigin(...): want %s, got %s", tc.reason, tc.wantOrigin, got)
+			}
+			if len(s.GetSchemaConflicts()) != 0 {
+				t.Errorf("\n%s\nGetSchemaConflicts(): want none, got %v", tc.reason, s.GetSchemaConflicts())
+			}
+			for _, d := range s.GetKindDefinitions(configMapGVK.GroupKind()) {
+				if d.Entry.Source == "a.yaml" {
+					t.Errorf("\n%s\nGetKindDefinitions(...): want definition of removed a.yaml to be unregistered", tc.reason)
+				}
+			}
+		})
+	}
+}
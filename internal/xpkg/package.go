@@ -36,6 +36,37 @@ func (p *Package) GetPackageDescriptor() *PackageEntry {
 	return nil
 }
 
+// ReplaceEntry replaces the entry with the same source as entry. Appends entry
+// if there is none. The entries are copied, so pointers to the previous
+// entries, for example of issues, keep pointing to them.
+func (p *Package) ReplaceEntry(entry PackageEntry) {
+	entries := make([]PackageEntry, 0, len(p.Entries)+1)
+	replaced := false
+	for _, e := range p.Entries {
+		if !replaced && e.Source == entry.Source {
+			e = entry
+			replaced = true
+		}
+		entries = append(entries, e)
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+	p.Entries = entries
+}
+
+// RemoveEntry removes all entries with the given source. The entries are
+// copied, so pointers to the previous entries keep pointing to them.
+func (p *Package) RemoveEntry(source string) {
+	entries := make([]PackageEntry, 0, len(p.Entries))
+	for _, e := range p.Entries {
+		if e.Source != source {
+			entries = append(entries, e)
+		}
+	}
+	p.Entries = entries
+}
+
 type PackageEntry struct {
 	// Object of this PackageEntry.
 	Object unstructured.Unstructured
@@ -8,53 +8,66 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/crossplane-contrib/crossplane-lint/internal/config"
-	"github.com/crossplane-contrib/crossplane-lint/internal/utils/sync"
 	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
 )
 
 const (
 	errLoadPackages = "failed to load packages"
 	errLoadPackage  = "failed to load package %s"
+
+	errFmtSchemaSource = "additionalSchemas[%d] requires either path or url"
 )
 
 func LoadPackageDependencies(deps []config.PackageDescriptor, parser PackageParser) ([]*xpkg.Package, error) {
-	resultChan, errorChan := loadPackageDependenciesConcurrently(deps, parser)
-	loadedDeps, errs := sync.CollectWithError(resultChan, errorChan)
-	for _, err := range errs {
-		fmt.Fprintln(os.Stderr, err.Error())
+	sources := make([]string, len(deps))
+	for i, d := range deps {
+		sources[i] = d.Image
 	}
-	if len(errs) > 0 {
-		return nil, errors.New(errLoadPackages)
+	return loadDependencies(sources, parser)
+}
+
+// LoadSchemaDependencies loads the CRDs and XRDs of deps with parser. See
+// NewSchemaParser.
+func LoadSchemaDependencies(deps []config.SchemaDescriptor, parser PackageParser) ([]*xpkg.Package, error) {
+	sources := make([]string, len(deps))
+	for i, d := range deps {
+		if (d.Path == "") == (d.URL == "") {
+			return nil, errors.Errorf(errFmtSchemaSource, i)
+		}
+		sources[i] = d.Source()
 	}
-	return loadedDeps, nil
+	return loadDependencies(sources, parser)
 }
 
-func loadPackageDependenciesConcurrently(deps []config.PackageDescriptor, parser PackageParser) (chan *xpkg.Package, chan error) {
-	errorChan := make(chan error)
-	resultChan := make(chan *xpkg.Package)
+// loadDependencies loads sources concurrently. The packages are returned in
+// the order of sources, so their schemas are registered deterministically.
+func loadDependencies(sources []string, parser PackageParser) ([]*xpkg.Package, error) {
+	loaded := make([]*xpkg.Package, len(sources))
+	errs := make([]error, len(sources))
 	eg := errgroup.Group{}
-	for _, d := range deps {
-		dep := d
+	for i, s := range sources {
+		i, source := i, s
 		eg.Go(func() error {
-			res, err := loadPackageDependency(dep, parser)
-			if err != nil {
-				errorChan <- err
-			} else {
-				resultChan <- res
-			}
-			return err
+			loaded[i], errs[i] = loadPackageDependency(source, parser)
+			return nil
 		})
 	}
+	_ = eg.Wait()
 
-	go func() {
-		_ = eg.Wait()
-		close(errorChan)
-		close(resultChan)
-	}()
-	return resultChan, errorChan
+	failed := false
+	for _, err := range errs {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			failed = true
+		}
+	}
+	if failed {
+		return nil, errors.New(errLoadPackages)
+	}
+	return loaded, nil
 }
 
-func loadPackageDependency(dep config.PackageDescriptor, parser PackageParser) (*xpkg.Package, error) {
-	depPkg, err := parser.ParsePackage(dep.Image)
-	return depPkg, errors.Wrapf(err, errLoadPackage, dep.Image)
+func loadPackageDependency(source string, parser PackageParser) (*xpkg.Package, error) {
+	depPkg, err := parser.ParsePackage(source)
+	return depPkg, errors.Wrapf(err, errLoadPackage, source)
 }
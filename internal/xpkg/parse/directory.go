@@ -39,7 +39,7 @@ func (p *PackageDirectoryParser) ParsePackage(directory string) (*xpkg.Package,
 
 	wg := sync.WaitGroup{}
 
-	pkg := &xpkg.Package{}
+	pkg := &xpkg.Package{Source: directory}
 	wg.Add(2)
 	go func() {
 		for res := range resultChan {
@@ -77,10 +77,9 @@ func (p *PackageDirectoryParser) parseFilesConcurrently(directory string) (chan
 			if err != nil {
 				return err
 			}
-			ext := filepath.Ext(path)
-			if !info.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			if !info.IsDir() && isManifestFile(path) {
 				eg.Go(func() error {
-					res, err := p.parseFile(path)
+					res, err := p.ParseFile(path)
 					if err != nil {
 						errChan <- err
 						return err
@@ -105,19 +104,30 @@ func (p *PackageDirectoryParser) parseFilesConcurrently(directory string) (chan
 	return resChan, errChan
 }
 
-func (p *PackageDirectoryParser) parseFile(path string) (xpkg.PackageEntry, error) {
+// ParseFile parses the manifest at path as a PackageEntry.
+func (p *PackageDirectoryParser) ParseFile(path string) (xpkg.PackageEntry, error) {
 	raw, err := afero.ReadFile(p.fs, path)
 	if err != nil {
 		return xpkg.PackageEntry{}, err
 	}
+	return ParsePackageEntry(raw, path)
+}
 
+// ParsePackageEntry parses a single manifest from raw.
+func ParsePackageEntry(raw []byte, source string) (xpkg.PackageEntry, error) {
 	o := unstructured.Unstructured{}
 	if err := yaml.Unmarshal(raw, &o); err != nil {
 		return xpkg.PackageEntry{}, err
 	}
 	return xpkg.PackageEntry{
 		Object: o,
-		Source: path,
+		Source: source,
 		Raw:    string(raw),
 	}, nil
 }
+
+// isManifestFile determines if path is a YAML file.
+func isManifestFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
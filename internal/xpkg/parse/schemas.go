@@ -0,0 +1,169 @@
+package parse
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+)
+
+const (
+	errFmtFetchSchemas       = "failed to fetch %s"
+	errFmtFetchSchemasStatus = "failed to fetch %s: %s"
+	errFmtReadSchemas        = "failed to read %s"
+	errFmtParseSchemas       = "failed to parse %s"
+	errStoreSchemasCache     = "failed to cache schemas"
+
+	// fetchTimeout is the maximum duration of a single download.
+	fetchTimeout = 30 * time.Second
+)
+
+var _ PackageParser = &SchemaParser{}
+
+// SchemaParser parses the CRDs and XRDs of local files, directories and
+// HTTP(S) URLs as a package. Other objects are ignored.
+type SchemaParser struct {
+	ctx     context.Context
+	fs      afero.Fs
+	cacheFs afero.Fs
+	client  *http.Client
+}
+
+// NewSchemaParser creates a new SchemaParser that reads local files from fs.
+// Documents fetched from URLs are cached in cacheFs if it is not nil.
+// Downloads are canceled when ctx is done.
+func NewSchemaParser(ctx context.Context, fs afero.Fs, cacheFs afero.Fs) *SchemaParser {
+	return &SchemaParser{
+		ctx:     ctx,
+		fs:      fs,
+		cacheFs: cacheFs,
+		client:  &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+// ParsePackage from source, which is either a URL, a manifest file or a
+// directory. Directories are searched recursively for YAML and JSON files.
+func (p *SchemaParser) ParsePackage(source string) (*xpkg.Package, error) {
+	if isURL(source) {
+		raw, err := p.fetch(source)
+		if err != nil {
+			return nil, err
+		}
+		return parseSchemas(raw, source, source)
+	}
+
+	files, err := p.schemaFiles(source)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtReadSchemas, source)
+	}
+	pkg := &xpkg.Package{Source: source, Name: source}
+	for _, file := range files {
+		raw, err := afero.ReadFile(p.fs, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtReadSchemas, file)
+		}
+		filePkg, err := parseSchemas(raw, file, source)
+		if err != nil {
+			return nil, err
+		}
+		pkg.Entries = append(pkg.Entries, filePkg.Entries...)
+	}
+	return pkg, nil
+}
+
+// schemaFiles returns source if it is a file and the manifest files within it
+// if it is a directory.
+func (p *SchemaParser) schemaFiles(source string) ([]string, error) {
+	info, err := p.fs.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{source}, nil
+	}
+	files := []string{}
+	err = afero.Walk(p.fs, source, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && (isManifestFile(path) || filepath.Ext(path) == ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// fetch the content of url. Fetched content is cached by url.
+func (p *SchemaParser) fetch(url string) ([]byte, error) {
+	cacheFile := cachedSchemasFileName(url)
+	if p.cacheFs != nil {
+		if raw, err := afero.ReadFile(p.cacheFs, cacheFile); err == nil {
+			return raw, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtFetchSchemas, url)
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtFetchSchemas, url)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf(errFmtFetchSchemasStatus, url, res.Status)
+	}
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtFetchSchemas, url)
+	}
+	if p.cacheFs != nil {
+		if err := p.cacheFs.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+			return nil, errors.Wrap(err, errStoreSchemasCache)
+		}
+		if err := afero.WriteFile(p.cacheFs, cacheFile, raw, 0644); err != nil {
+			return nil, errors.Wrap(err, errStoreSchemasCache)
+		}
+	}
+	return raw, nil
+}
+
+// parseSchemas parses the CRDs and XRDs of the YAML stream raw read from file.
+func parseSchemas(raw []byte, file, source string) (*xpkg.Package, error) {
+	all, err := parsePackage(bytes.NewReader(raw), file)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtParseSchemas, file)
+	}
+	pkg := &xpkg.Package{Source: source, Name: source}
+	for _, e := range all.Entries {
+		if e.IsCRD() || e.IsXRD() {
+			pkg.Entries = append(pkg.Entries, e)
+		}
+	}
+	return pkg, nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+func cachedSchemasFileName(url string) string {
+	sum := md5.Sum([]byte(url))
+	return fmt.Sprintf("%s.yaml", hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,90 @@
+package parse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const schemasCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: buckets.s3.aws.upbound.io
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+`
+
+func TestSchemaParserFetch(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stalled" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		_, _ = w.Write([]byte(schemasCRD))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := map[string]struct {
+		reason      string
+		ctx         context.Context
+		url         string
+		wantEntries int
+		wantErr     bool
+	}{
+		"Fetched": {
+			reason:      "The CRDs of fetched documents are parsed.",
+			ctx:         context.Background(),
+			url:         server.URL + "/crds.yaml",
+			wantEntries: 1,
+		},
+		"Canceled": {
+			reason:  "Downloads stop when the context is done.",
+			ctx:     canceled,
+			url:     server.URL + "/stalled",
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := NewSchemaParser(tc.ctx, afero.NewMemMapFs(), nil)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				pkg, err := p.ParsePackage(tc.url)
+				if tc.wantErr {
+					if err == nil {
+						t.Errorf("\n%s\nParsePackage(...): want error, got nil", tc.reason)
+					}
+					return
+				}
+				if err != nil {
+					t.Errorf("\n%s\nParsePackage(...): unexpected error: %v", tc.reason, err)
+					return
+				}
+				if len(pkg.Entries) != tc.wantEntries {
+					t.Errorf("\n%s\nParsePackage(...): want %d entries, got %d", tc.reason, tc.wantEntries, len(pkg.Entries))
+				}
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("\n%s\nParsePackage(...): did not return", tc.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package parse
+
+import (
+	"io/fs"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// FileChanges are the manifest files of a directory that changed between two
+// polls of a DirectoryWatcher.
+type FileChanges struct {
+	// Modified contains the files that were created or modified.
+	Modified []string
+
+	// Removed contains the files that were removed.
+	Removed []string
+}
+
+// Empty determines if c contains no changes.
+func (c FileChanges) Empty() bool {
+	return len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+// DirectoryWatcher detects changes of the manifest files in a directory by
+// polling the file system. Polling is used over file system notifications to
+// support any afero.Fs.
+type DirectoryWatcher struct {
+	fs        afero.Fs
+	directory string
+	files     map[string]fileState
+}
+
+// NewDirectoryWatcher creates a new DirectoryWatcher for directory. The first
+// call of Poll reports all manifest files as modified.
+func NewDirectoryWatcher(fs afero.Fs, directory string) *DirectoryWatcher {
+	return &DirectoryWatcher{
+		fs:        fs,
+		directory: directory,
+		files:     map[string]fileState{},
+	}
+}
+
+// Poll returns the files that changed since the last call of Poll.
+func (w *DirectoryWatcher) Poll() (FileChanges, error) {
+	files := map[string]fileState{}
+	err := afero.Walk(w.fs, w.directory, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isManifestFile(path) {
+			files[path] = fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		}
+		return nil
+	})
+	if err != nil {
+		return FileChanges{}, err
+	}
+
+	changes := FileChanges{}
+	for path, state := range files {
+		if previous, exists := w.files[path]; !exists || previous != state {
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	for path := range w.files {
+		if _, exists := files[path]; !exists {
+			changes.Removed = append(changes.Removed, path)
+		}
+	}
+	sort.Strings(changes.Modified)
+	sort.Strings(changes.Removed)
+	w.files = files
+	return changes, nil
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// Formats of reports.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+const errFmtUnknownFormat = "unknown stats format '%s', expected '%s' or '%s'"
+
+// Write r to w in format.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatTable:
+		return r.WriteTable(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	}
+	return errors.Errorf(errFmtUnknownFormat, format, FormatTable, FormatJSON)
+}
+
+// WriteTable writes r as table with a row per composition to w, followed by
+// a table with a row per XRD version if r has field usages.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPOSITION\tRESOURCES\tPATCHES\tPATCHSETS\tPATCHSET USES\tTRANSFORM DEPTH\tKINDS\tPROVIDERS\tSIZE\tPATCH TYPES")
+	for _, s := range r.Compositions {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			s.Name, s.Resources, s.Patches, s.PatchSets, s.PatchSetUses, s.TransformDepth,
+			len(s.Kinds), len(s.Providers), s.Size, formatPatchTypes(s.PatchesByType))
+	}
+	if len(r.FieldUsage) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "COMPOSITE TYPE\tSPEC FIELDS CONSUMED\tSTATUS FIELDS POPULATED")
+		for _, u := range r.FieldUsage {
+			fmt.Fprintf(tw, "%s\t%d/%d\t%d/%d\n", u.CompositeType, u.SpecFieldsConsumed, u.SpecFields, u.StatusFieldsPopulated, u.StatusFields)
+		}
+	}
+	return tw.Flush()
+}
+
+// formatPatchTypes formats the number of patches by type, for example
+// FromCompositeFieldPath=3,PatchSet=1.
+func formatPatchTypes(counts map[string]int) string {
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%s=%d", t, counts[t])
+	}
+	return strings.Join(parts, ",")
+}
+
+// WriteJSON writes r as JSON object to w.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
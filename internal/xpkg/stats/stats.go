@@ -0,0 +1,163 @@
+// Package stats computes complexity metrics of the compositions of a package.
+package stats
+
+import (
+	"sort"
+
+	xpv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+)
+
+// CompositionStats are the complexity metrics of a Composition.
+type CompositionStats struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+
+	// CompositeType is the group kind of compositeTypeRef.
+	CompositeType string `json:"compositeType"`
+
+	// Resources is the number of composed resources, including those of
+	// function-patch-and-transform steps.
+	Resources int `json:"resources"`
+
+	// Patches is the number of patches of resources and patch sets.
+	Patches int `json:"patches"`
+
+	// PatchesByType is the number of patches by patch type.
+	PatchesByType map[string]int `json:"patchesByType"`
+
+	// PatchSets is the number of defined patch sets.
+	PatchSets int `json:"patchSets"`
+
+	// PatchSetUses is the number of patches of resources that include a
+	// patch set.
+	PatchSetUses int `json:"patchSetUses"`
+
+	// TransformDepth is the highest number of transforms of a patch.
+	TransformDepth int `json:"transformDepth"`
+
+	// Kinds are the distinct composed kinds.
+	Kinds []string `json:"kinds"`
+
+	// Providers are the distinct packages providing the composed kinds.
+	// Kinds of the package itself and kinds without schema are not
+	// included.
+	Providers []string `json:"providers"`
+
+	// Size of the manifest in bytes.
+	Size int `json:"size"`
+}
+
+// FieldUsage is the coverage of the fields of a composite resource version
+// by the patches of the compositions of a package. Fields are counted as
+// leaf fields of the schema.
+type FieldUsage struct {
+	// CompositeType is the group version kind of the composite resource.
+	CompositeType string `json:"compositeType"`
+
+	SpecFields            int `json:"specFields"`
+	SpecFieldsConsumed    int `json:"specFieldsConsumed"`
+	StatusFields          int `json:"statusFields"`
+	StatusFieldsPopulated int `json:"statusFieldsPopulated"`
+}
+
+// Report holds the stats of all compositions of a package sorted by name and
+// the field usage of its XRD versions sorted by composite type.
+type Report struct {
+	Compositions []CompositionStats `json:"compositions"`
+	FieldUsage   []FieldUsage       `json:"fieldUsage,omitempty"`
+}
+
+// Compute the stats of the compositions of pkg. The packages providing
+// composed kinds are looked up in origins, if it is not nil.
+func Compute(pkg *xpkg.Package, origins lint.SchemaOrigins) *Report {
+	r := &Report{Compositions: []CompositionStats{}}
+	for i := range pkg.Entries {
+		e := &pkg.Entries[i]
+		if !e.IsComposition() {
+			continue
+		}
+		comp, err := e.AsComposition()
+		if err != nil {
+			continue
+		}
+		r.Compositions = append(r.Compositions, ComputeComposition(pkg, e, comp, origins))
+	}
+	sort.Slice(r.Compositions, func(i, j int) bool {
+		return r.Compositions[i].Name < r.Compositions[j].Name
+	})
+	return r
+}
+
+// ComputeComposition computes the stats of the composition comp of the entry
+// e of pkg.
+func ComputeComposition(pkg *xpkg.Package, e *xpkg.PackageEntry, comp *xpv1.Composition, origins lint.SchemaOrigins) CompositionStats {
+	s := CompositionStats{
+		Name:          comp.GetName(),
+		Source:        e.Source,
+		PatchesByType: map[string]int{},
+		Size:          len(e.Raw),
+	}
+	if gv, err := schema.ParseGroupVersion(comp.Spec.CompositeTypeRef.APIVersion); err == nil {
+		s.CompositeType = gv.WithKind(comp.Spec.CompositeTypeRef.Kind).GroupKind().String()
+	}
+
+	kinds := map[string]bool{}
+	providers := map[string]bool{}
+	for _, t := range xpkg.GetComposedTemplates(e, comp) {
+		s.PatchSets += len(t.PatchSets)
+		for _, ps := range t.PatchSets {
+			s.addPatches(ps.Patches)
+		}
+		s.Resources += len(t.Resources)
+		for _, r := range t.Resources {
+			s.addPatches(r.Patches)
+			base := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal(r.Base.Raw, base); err != nil || base.GetAPIVersion() == "" || base.GetKind() == "" {
+				continue
+			}
+			gvk := base.GroupVersionKind()
+			kinds[gvk.GroupKind().String()] = true
+			if origins == nil {
+				continue
+			}
+			if origin := origins.GetSchemaOrigin(gvk); origin != nil && origin.Package != "" && origin.Package != pkg.Source {
+				providers[origin.Package] = true
+			}
+		}
+	}
+	s.Kinds = sortedKeys(kinds)
+	s.Providers = sortedKeys(providers)
+	return s
+}
+
+func (s *CompositionStats) addPatches(patches []xpv1.Patch) {
+	for _, p := range patches {
+		t := p.Type
+		if t == "" {
+			t = xpv1.PatchTypeFromCompositeFieldPath
+		}
+		s.Patches++
+		s.PatchesByType[string(t)]++
+		if t == xpv1.PatchTypePatchSet {
+			s.PatchSetUses++
+		}
+		if len(p.Transforms) > s.TransformDepth {
+			s.TransformDepth = len(p.Transforms)
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
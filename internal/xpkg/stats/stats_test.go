@@ -0,0 +1,124 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+const composition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: cluster
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XCluster
+  patchSets:
+  - name: common
+    patches:
+    - fromFieldPath: spec.region
+      toFieldPath: spec.forProvider.region
+  resources:
+  - name: cluster
+    base:
+      apiVersion: eks.aws.upbound.io/v1beta1
+      kind: Cluster
+    patches:
+    - type: PatchSet
+      patchSetName: common
+    - fromFieldPath: spec.version
+      toFieldPath: spec.forProvider.version
+      transforms:
+      - type: string
+        string:
+          fmt: "v%s"
+      - type: string
+        string:
+          type: Convert
+          convert: ToLower
+    - type: ToCompositeFieldPath
+      fromFieldPath: status.atProvider.arn
+      toFieldPath: status.arn
+  - name: network
+    base:
+      apiVersion: example.org/v1
+      kind: XNetwork
+  - name: incomplete
+    base:
+      kind: Bucket
+`
+
+// fakeOrigins defines kinds in packages by group kind.
+type fakeOrigins map[schema.GroupKind]string
+
+func (o fakeOrigins) GetSchemaOrigin(gvk schema.GroupVersionKind) *lint.SchemaOrigin {
+	pkg, exists := o[gvk.GroupKind()]
+	if !exists {
+		return nil
+	}
+	return &lint.SchemaOrigin{Package: pkg}
+}
+
+func TestCompute(t *testing.T) {
+	e, err := parse.ParsePackageEntry([]byte(composition), "composition.yaml")
+	if err != nil {
+		t.Fatalf("ParsePackageEntry(...): unexpected error: %v", err)
+	}
+	pkg := &xpkg.Package{Source: "pkg", Entries: []xpkg.PackageEntry{e}}
+	base := CompositionStats{
+		Name:          "cluster",
+		Source:        "composition.yaml",
+		CompositeType: "XCluster.example.org",
+		Resources:     3,
+		Patches:       4,
+		PatchesByType: map[string]int{
+			"FromCompositeFieldPath": 2,
+			"PatchSet":               1,
+			"ToCompositeFieldPath":   1,
+		},
+		PatchSets:      1,
+		PatchSetUses:   1,
+		TransformDepth: 2,
+		Kinds:          []string{"Cluster.eks.aws.upbound.io", "XNetwork.example.org"},
+		Providers:      []string{},
+		Size:           len(composition),
+	}
+
+	cases := map[string]struct {
+		reason        string
+		origins       lint.SchemaOrigins
+		wantProviders []string
+	}{
+		"WithoutOrigins": {
+			reason:        "Providers are unknown without origins. Bases without apiVersion are skipped.",
+			wantProviders: []string{},
+		},
+		"WithOrigins": {
+			reason: "Kinds defined by other packages count as providers, kinds of the package itself do not.",
+			origins: fakeOrigins{
+				{Group: "eks.aws.upbound.io", Kind: "Cluster"}: "provider-aws-eks",
+				{Group: "example.org", Kind: "XNetwork"}:       "pkg",
+			},
+			wantProviders: []string{"provider-aws-eks"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			want := base
+			want.Providers = tc.wantProviders
+			got := Compute(pkg, tc.origins)
+			if len(got.Compositions) != 1 {
+				t.Fatalf("\n%s\nCompute(...): want 1 composition, got %d", tc.reason, len(got.Compositions))
+			}
+			if !reflect.DeepEqual(want, got.Compositions[0]) {
+				t.Errorf("\n%s\nCompute(...): want %+v, got %+v", tc.reason, want, got.Compositions[0])
+			}
+		})
+	}
+}
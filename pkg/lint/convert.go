@@ -0,0 +1,272 @@
+package lint
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	internallint "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/jsonpath"
+)
+
+const (
+	errFmtParsePath = "cannot parse path '%s'"
+)
+
+// converter converts between the types of this package and the types used by
+// the linter internally. It remembers the entries it converted, so the
+// entries of issues point to the entries of the linted Package.
+type converter struct {
+	mu       sync.Mutex
+	packages map[*Package]*xpkg.Package
+	internal map[*PackageEntry]*xpkg.PackageEntry
+	public   map[*xpkg.PackageEntry]*PackageEntry
+
+	// contents are the entries of converted packages by content. Rules may
+	// report copies of entries, which are found by their content.
+	contents map[entryContent]*PackageEntry
+}
+
+type entryContent struct {
+	source string
+	raw    string
+}
+
+func newConverter() *converter {
+	return &converter{
+		packages: map[*Package]*xpkg.Package{},
+		internal: map[*PackageEntry]*xpkg.PackageEntry{},
+		public:   map[*xpkg.PackageEntry]*PackageEntry{},
+		contents: map[entryContent]*PackageEntry{},
+	}
+}
+
+// internalPackage returns the internal representation of pkg. The previous
+// conversion is reused if the entries of pkg did not change since, so schemas
+// registered for pkg refer to the same entries as the issues of pkg.
+func (c *converter) internalPackage(pkg *Package) *xpkg.Package {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.packages[pkg]
+	if ok && c.isCurrent(pkg, p) {
+		return p
+	}
+	if !ok {
+		// The same internal package is updated when pkg changes, so it stays
+		// registered with the schema store.
+		p = &xpkg.Package{}
+		c.packages[pkg] = p
+	}
+	p.Source = pkg.Source
+	p.Name = pkg.Name
+	p.Entries = make([]xpkg.PackageEntry, len(pkg.Entries))
+	for i, e := range pkg.Entries {
+		p.Entries[i] = xpkg.PackageEntry{
+			Source: e.Source,
+			Raw:    e.Raw,
+			Object: unstructured.Unstructured{Object: e.Object},
+		}
+	}
+	for i := range pkg.Entries {
+		c.internal[&pkg.Entries[i]] = &p.Entries[i]
+		c.public[&p.Entries[i]] = &pkg.Entries[i]
+		content := entryContent{source: pkg.Entries[i].Source, raw: pkg.Entries[i].Raw}
+		if _, exists := c.contents[content]; !exists {
+			c.contents[content] = &pkg.Entries[i]
+		}
+	}
+	return p
+}
+
+func (c *converter) isCurrent(pkg *Package, p *xpkg.Package) bool {
+	if len(pkg.Entries) != len(p.Entries) {
+		return false
+	}
+	for i := range pkg.Entries {
+		if c.public[&p.Entries[i]] != &pkg.Entries[i] || pkg.Entries[i].Raw != p.Entries[i].Raw {
+			return false
+		}
+	}
+	return true
+}
+
+// internalEntry returns the internal representation of e. Entries that are
+// not part of a converted package are converted on their own.
+func (c *converter) internalEntry(e *PackageEntry) *xpkg.PackageEntry {
+	if e == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ie, ok := c.internal[e]; ok {
+		return ie
+	}
+	ie := &xpkg.PackageEntry{
+		Source: e.Source,
+		Raw:    e.Raw,
+		Object: unstructured.Unstructured{Object: e.Object},
+	}
+	c.internal[e] = ie
+	c.public[ie] = e
+	return ie
+}
+
+// publicEntry returns the entry that e or an entry with the same content was
+// converted from. Entries that are not part of a converted package, for
+// example of dependencies, are converted on their own.
+func (c *converter) publicEntry(e *xpkg.PackageEntry) *PackageEntry {
+	if e == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pe, ok := c.public[e]; ok {
+		return pe
+	}
+	if pe, ok := c.contents[entryContent{source: e.Source, raw: e.Raw}]; ok {
+		return pe
+	}
+	pe := newPackageEntry(e)
+	c.public[e] = &pe
+	c.internal[&pe] = e
+	return &pe
+}
+
+func (c *converter) publicReport(report internallint.LinterReport) Report {
+	r := Report{Issues: make([]Issue, len(report.Issues))}
+	for i, iss := range report.Issues {
+		r.Issues[i] = c.publicIssue(iss)
+	}
+	return r
+}
+
+func (c *converter) internalReport(report Report) (internallint.LinterReport, error) {
+	r := internallint.LinterReport{Issues: make([]internallint.Issue, len(report.Issues))}
+	for i, iss := range report.Issues {
+		internal, err := c.internalIssue(iss)
+		if err != nil {
+			return internallint.LinterReport{}, err
+		}
+		r.Issues[i] = internal
+	}
+	return r, nil
+}
+
+func (c *converter) publicIssue(iss internallint.Issue) Issue {
+	issue := Issue{
+		RuleName:    iss.RuleName,
+		Severity:    Severity(iss.Severity),
+		Entry:       c.publicEntry(iss.Entry),
+		Path:        publicPath(iss.Path),
+		PathValue:   iss.PathValue,
+		Description: iss.Description,
+		Suggestions: iss.Suggestions,
+		Schema:      iss.Schema,
+	}
+	for _, l := range iss.Related {
+		issue.Related = append(issue.Related, Location{
+			Entry: c.publicEntry(l.Entry),
+			Path:  publicPath(l.Path),
+		})
+	}
+	for _, f := range iss.Fixes {
+		issue.Fixes = append(issue.Fixes, Fix{
+			Type:        FixType(f.Type),
+			Description: f.Description,
+			Path:        publicPath(f.Path),
+			Key:         f.Key,
+			Value:       f.Value,
+		})
+	}
+	return issue
+}
+
+func (c *converter) internalIssue(iss Issue) (internallint.Issue, error) {
+	path, err := internalPath(iss.Path)
+	if err != nil {
+		return internallint.Issue{}, err
+	}
+	fixes, err := internalFixes(iss.Fixes)
+	if err != nil {
+		return internallint.Issue{}, err
+	}
+	issue := internallint.Issue{
+		RuleName:    iss.RuleName,
+		Severity:    internallint.Severity(iss.Severity),
+		Entry:       c.internalEntry(iss.Entry),
+		Path:        path,
+		PathValue:   iss.PathValue,
+		Description: iss.Description,
+		Suggestions: iss.Suggestions,
+		Fixes:       fixes,
+		Schema:      iss.Schema,
+	}
+	for _, l := range iss.Related {
+		path, err := internalPath(l.Path)
+		if err != nil {
+			return internallint.Issue{}, err
+		}
+		issue.Related = append(issue.Related, internallint.Location{
+			Entry: c.internalEntry(l.Entry),
+			Path:  path,
+		})
+	}
+	return issue, nil
+}
+
+func publicOrigin(o internallint.SchemaOrigin) SchemaOrigin {
+	origin := SchemaOrigin{
+		Package: o.Package,
+		Kind:    o.Kind,
+		Name:    o.Name,
+	}
+	if o.Entry != nil {
+		origin.Source = o.Entry.Source
+	}
+	return origin
+}
+
+func publicRunError(err *internallint.RunError) *RunError {
+	runErr := &RunError{Errors: make([]RuleError, len(err.Errors))}
+	for i, e := range err.Errors {
+		runErr.Errors[i] = RuleError{RuleName: e.RuleName, Err: e.Err}
+	}
+	return runErr
+}
+
+func internalFixes(fixes []Fix) ([]internallint.Fix, error) {
+	var converted []internallint.Fix
+	for _, f := range fixes {
+		path, err := internalPath(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, internallint.Fix{
+			Type:        internallint.FixType(f.Type),
+			Description: f.Description,
+			Path:        path,
+			Key:         f.Key,
+			Value:       f.Value,
+		})
+	}
+	return converted, nil
+}
+
+// publicPath formats path in the field path notation of Crossplane patches.
+func publicPath(path jsonpath.JSONPath) string {
+	return strings.TrimPrefix(path.String(), ".")
+}
+
+func internalPath(path string) (jsonpath.JSONPath, error) {
+	if path == "" {
+		return nil, nil
+	}
+	p, err := jsonpath.Parse(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtParsePath, path)
+	}
+	return p, nil
+}
@@ -0,0 +1,269 @@
+// Package lint is the public Go API of crossplane-lint. It allows embedding
+// the linter in other tools.
+//
+//	pkg, err := lint.ParseDirectory(afero.NewOsFs(), "package")
+//	if err != nil {
+//		return err
+//	}
+//	report, err := lint.Lint(ctx, pkg,
+//		lint.WithRule("house.checkLabels", lint.RuleFunc(checkLabels)),
+//	)
+//
+// # Compatibility
+//
+// This package follows semantic versioning. Within a major version, releases
+// do not remove or change its exported identifiers incompatibly. Minor
+// releases may add
+//
+//   - fields to structs, so they should be created with field names,
+//   - functions, options and constants, and
+//   - methods to Context, which is only implemented by the linter.
+//
+// The set of default rules, the issues they report and their descriptions
+// are not part of the API and may change with any release. Other packages of
+// this module are internal and not covered by these guarantees.
+package lint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	internallint "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/fix"
+	linter "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter"
+)
+
+const (
+	errFmtRuleFailed = "rule '%s' failed to run: %v"
+)
+
+// Severity of an Issue.
+type Severity string
+
+const (
+	// SeverityError is used for issues that break a package.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is used for issues that are likely unintended but do
+	// not break a package.
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo is used for informational issues.
+	SeverityInfo Severity = "info"
+)
+
+// Report is the result of a linter run.
+type Report struct {
+	Issues []Issue
+}
+
+// Issue is a problem discovered by a rule.
+type Issue struct {
+	// RuleName is the name of the rule that reported the issue.
+	RuleName string
+
+	Severity Severity
+
+	// Entry the issue was found in. Points to an element of the Entries of
+	// the linted Package.
+	Entry *PackageEntry
+
+	// Path of the affected field of Entry in the field path notation of
+	// Crossplane patches, for example spec.resources[0].base. Empty for the
+	// whole entry.
+	Path string
+
+	// PathValue is the value at Path, if any.
+	PathValue string
+
+	Description string
+
+	// Related are other locations that are affected by the issue, for
+	// example the resources that use a broken patch set.
+	Related []Location
+
+	// Suggestions are values that may replace PathValue to resolve the
+	// issue, most likely first.
+	Suggestions []string
+
+	// Fixes that resolve the issue when applied together with ApplyFixes.
+	// Only set if the fix is unambiguous.
+	Fixes []Fix
+
+	// Schema the issue was validated against, see SchemaOrigin.String. Empty
+	// if the issue is not about a schema.
+	Schema string
+}
+
+// Location is a path within a PackageEntry.
+type Location struct {
+	Entry *PackageEntry
+
+	// Path in the field path notation of Crossplane patches.
+	Path string
+}
+
+// FixType is the type of operation of a Fix.
+type FixType string
+
+const (
+	// FixTypeSetValue replaces the scalar at Fix.Path with Fix.Value.
+	FixTypeSetValue FixType = "SetValue"
+
+	// FixTypeAddField adds the field Fix.Key with Fix.Value to the mapping
+	// at Fix.Path.
+	FixTypeAddField FixType = "AddField"
+)
+
+// Fix is a change of the manifest of an issue's entry. See ApplyFixes.
+type Fix struct {
+	Type        FixType
+	Description string
+
+	// Path in the field path notation of Crossplane patches.
+	Path  string
+	Key   string
+	Value string
+}
+
+// RuleError is the failure of a single rule to run, for example of a plugin
+// that crashed.
+type RuleError struct {
+	RuleName string
+	Err      error
+}
+
+func (e RuleError) Error() string {
+	return fmt.Sprintf(errFmtRuleFailed, e.RuleName, e.Err)
+}
+
+func (e RuleError) Unwrap() error {
+	return e.Err
+}
+
+// RunError is returned by Lint if rules failed to run. The issues of the
+// report are incomplete in this case.
+type RunError struct {
+	// Errors of the failed rules sorted by rule name.
+	Errors []RuleError
+}
+
+func (e *RunError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ApplyFixes applies fixes to the manifest raw of a single PackageEntry and
+// returns the fixed manifest. Comments and formatting are preserved. Either
+// all fixes are applied or none.
+func ApplyFixes(raw []byte, fixes []Fix) ([]byte, error) {
+	converted, err := internalFixes(fixes)
+	if err != nil {
+		return nil, err
+	}
+	return fix.Apply(raw, converted)
+}
+
+// DefaultRuleNames returns the names of the rules that are enabled by default.
+func DefaultRuleNames() []string {
+	return linter.DefaultRuleNames()
+}
+
+// Option configures a linter run.
+type Option func(o *options)
+
+type options struct {
+	store *SchemaStore
+
+	// linterOpts are created for each run, so rules can be adapted to the
+	// linted package.
+	linterOpts []func(conv *converter, pkg *Package) linter.LinterOption
+}
+
+// WithSchemaStore lints with store. store must contain the schemas of the
+// linted package and its dependencies. By default a new SchemaStore with the
+// bundled Kubernetes schemas and the schemas of the linted package is used.
+func WithSchemaStore(store *SchemaStore) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// WithRule adds rule as name. Replaces the default rule of the same name.
+func WithRule(name string, rule Rule) Option {
+	return func(o *options) {
+		o.linterOpts = append(o.linterOpts, func(conv *converter, pkg *Package) linter.LinterOption {
+			if r, ok := rule.(internalRule); ok {
+				return linter.WithRule(name, r.rule)
+			}
+			return linter.WithRule(name, ruleAdapter{rule: rule, conv: conv, pkg: pkg})
+		})
+	}
+}
+
+// WithSeverity overrides the severity of all issues of the rule name.
+func WithSeverity(name string, severity Severity) Option {
+	return func(o *options) {
+		o.linterOpts = append(o.linterOpts, func(*converter, *Package) linter.LinterOption {
+			return linter.WithSeverity(name, internallint.Severity(severity))
+		})
+	}
+}
+
+// WithoutRule disables the rule name.
+func WithoutRule(name string) Option {
+	return func(o *options) {
+		o.linterOpts = append(o.linterOpts, func(*converter, *Package) linter.LinterOption {
+			return linter.WithoutRule(name)
+		})
+	}
+}
+
+// WithoutDefaultRules disables all default rules. Rules added with WithRule
+// are kept.
+func WithoutDefaultRules() Option {
+	return func(o *options) {
+		for _, name := range linter.DefaultRuleNames() {
+			name := name
+			without := func(*converter, *Package) linter.LinterOption {
+				return linter.WithoutRule(name)
+			}
+			o.linterOpts = append([]func(*converter, *Package) linter.LinterOption{without}, o.linterOpts...)
+		}
+	}
+}
+
+// Lint pkg with the default rules and the rules added by opts. Returns the
+// issues discovered so far and the error of ctx if ctx is done before all
+// rules finished. Returns the issues of all rules and a *RunError if rules
+// failed to run.
+func Lint(ctx context.Context, pkg *Package, opts ...Option) (Report, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.store == nil {
+		o.store = NewSchemaStore()
+		o.store.RegisterBundledKubernetesSchemas()
+		// Definitions that cannot be converted are skipped. XRDs among them
+		// are reported by xrd.checkNames.
+		o.store.RegisterPackage(pkg)
+	}
+	conv := o.store.conv
+	linterOpts := make([]linter.LinterOption, len(o.linterOpts))
+	for i, opt := range o.linterOpts {
+		linterOpts[i] = opt(conv, pkg)
+	}
+	report, err := linter.Newlinter(o.store.store, linterOpts...).Lint(ctx, conv.internalPackage(pkg))
+	var runErr *internallint.RunError
+	if errors.As(err, &runErr) {
+		return conv.publicReport(report), publicRunError(runErr)
+	}
+	return conv.publicReport(report), err
+}
@@ -0,0 +1,334 @@
+package lint_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/pkg/lint"
+)
+
+const testXRD = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xexamples.example.org
+spec:
+  group: example.org
+  names:
+    kind: XExample
+    plural: xexamples
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              region:
+                type: string
+`
+
+const testComposition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: example
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1
+    kind: XExample
+  resources:
+  - name: config
+    base:
+      apiVersion: v1
+      kind: ConfigMap
+    patches:
+    - fromFieldPath: spec.regoin
+      toFieldPath: data.region
+`
+
+const testConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: example
+data:
+  key: value
+`
+
+// parseTestPackage writes manifests to the files 0.yaml, 1.yaml and so on of
+// a directory and parses it.
+func parseTestPackage(t *testing.T, manifests ...string) *lint.Package {
+	t.Helper()
+	fs := afero.NewMemMapFs()
+	for i, m := range manifests {
+		path := filepath.Join("pkg", fmt.Sprintf("%d.yaml", i))
+		if err := afero.WriteFile(fs, path, []byte(m), 0o644); err != nil {
+			t.Fatalf("WriteFile(...): unexpected error: %v", err)
+		}
+	}
+	pkg, err := lint.ParseDirectory(fs, "pkg")
+	if err != nil {
+		t.Fatalf("ParseDirectory(...): unexpected error: %v", err)
+	}
+	sort.Slice(pkg.Entries, func(i, j int) bool {
+		return pkg.Entries[i].Source < pkg.Entries[j].Source
+	})
+	return pkg
+}
+
+// formatIssues formats issues as "<rule> <severity> <entry> <path>: <desc>"
+// sorted, where entry is the index of the issue's entry in pkg or -1 if it
+// is not an entry of pkg.
+func formatIssues(pkg *lint.Package, issues []lint.Issue) []string {
+	formatted := []string{}
+	for _, iss := range issues {
+		entry := -1
+		for i := range pkg.Entries {
+			if iss.Entry == &pkg.Entries[i] {
+				entry = i
+			}
+		}
+		formatted = append(formatted, fmt.Sprintf("%s %s %d %s: %s", iss.RuleName, iss.Severity, entry, iss.Path, iss.Description))
+	}
+	sort.Strings(formatted)
+	return formatted
+}
+
+func TestParseDirectory(t *testing.T) {
+	pkg := parseTestPackage(t, testXRD, testConfigMap)
+
+	got := []string{}
+	for _, e := range pkg.Entries {
+		got = append(got, fmt.Sprintf("%s %s", filepath.Base(e.Source), e.Object["kind"]))
+	}
+	want := []string{"0.yaml CompositeResourceDefinition", "1.yaml ConfigMap"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("ParseDirectory(...): want entries %q, got %q", want, got)
+	}
+	if pkg.Entries[1].Raw != testConfigMap {
+		t.Errorf("ParseDirectory(...): want raw %q, got %q", testConfigMap, pkg.Entries[1].Raw)
+	}
+}
+
+func TestLint(t *testing.T) {
+	checkData := lint.RuleFunc(func(ctx lint.Context, pkg *lint.Package) {
+		for i := range pkg.Entries {
+			if pkg.Entries[i].Object["kind"] == "ConfigMap" {
+				ctx.ReportIssue(lint.Issue{Entry: &pkg.Entries[i], Path: "data.key", Description: "data is not allowed"})
+			}
+		}
+	})
+	customRule, err := lint.NewCustomRule(lint.CustomRuleSpec{
+		Name:       "data",
+		Severity:   lint.SeverityWarning,
+		Match:      lint.CustomRuleMatch{Kind: "ConfigMap"},
+		Expression: `object.data.key == "other"`,
+		Message:    "key must be other",
+		Path:       "data.key",
+	})
+	if err != nil {
+		t.Fatalf("NewCustomRule(...): unexpected error: %v", err)
+	}
+
+	cases := map[string]struct {
+		reason    string
+		manifests []string
+		opts      []lint.Option
+		want      []string
+		wantErr   string
+	}{
+		"DefaultRules": {
+			reason:    "The default rules report issues of the package with paths in field path notation.",
+			manifests: []string{testXRD, testComposition},
+			want: []string{
+				"composition.checkPathFieldPaths error 1 spec.resources[0].patches[0].fromFieldPath: property 'regoin' not found, did you mean 'region'?",
+				"xrd.checkFieldUsage warning 0 spec.versions[0].schema.openAPIV3Schema.properties.spec.properties.region: spec field 'spec.region' is never consumed by any composition",
+			},
+		},
+		"RuleFunc": {
+			reason:    "Issues of rules point to the entries of the linted package and get the rule's name and default severity.",
+			manifests: []string{testXRD, testConfigMap},
+			opts:      []lint.Option{lint.WithoutDefaultRules(), lint.WithRule("house.checkData", checkData)},
+			want:      []string{"house.checkData error 1 data.key: data is not allowed"},
+		},
+		"Severity": {
+			reason:    "WithSeverity overrides the severity of the issues of a rule.",
+			manifests: []string{testConfigMap},
+			opts: []lint.Option{
+				lint.WithoutDefaultRules(),
+				lint.WithRule("house.checkData", checkData),
+				lint.WithSeverity("house.checkData", lint.SeverityInfo),
+			},
+			want: []string{"house.checkData info 0 data.key: data is not allowed"},
+		},
+		"WithoutRule": {
+			reason:    "WithoutRule removes a rule that was added before.",
+			manifests: []string{testConfigMap},
+			opts: []lint.Option{
+				lint.WithoutDefaultRules(),
+				lint.WithRule("house.checkData", checkData),
+				lint.WithoutRule("house.checkData"),
+			},
+			want: []string{},
+		},
+		"CustomRule": {
+			reason:    "Rules created by NewCustomRule report issues like the rules of the linter.",
+			manifests: []string{testConfigMap},
+			opts:      []lint.Option{lint.WithoutDefaultRules(), lint.WithRule("custom.data", customRule)},
+			want:      []string{"custom.data warning 0 data.key: key must be other"},
+		},
+		"Schemas": {
+			reason:    "Rules can look up the schemas and origins of the kinds of the package.",
+			manifests: []string{testXRD},
+			opts: []lint.Option{lint.WithoutDefaultRules(), lint.WithRule("house.checkSchema", lint.RuleFunc(func(ctx lint.Context, pkg *lint.Package) {
+				props := ctx.GetSchema("example.org/v1", "XExample")
+				origin := ctx.GetSchemaOrigin("example.org/v1", "XExample")
+				if props == nil || origin == nil {
+					return
+				}
+				region := props.Properties["spec"].Properties["region"]
+				ctx.ReportIssue(lint.Issue{
+					Entry:       &pkg.Entries[0],
+					Description: fmt.Sprintf("region of %s in %s is %s", origin.String(), filepath.Base(origin.Source), region.Type),
+				})
+			}))},
+			want: []string{"house.checkSchema error 0 : region of pkg XRD xexamples.example.org in 0.yaml is string"},
+		},
+		"RuleError": {
+			reason:    "Errors reported by rules fail the run with a RunError. The issues of the other rules are returned.",
+			manifests: []string{testConfigMap},
+			opts: []lint.Option{
+				lint.WithoutDefaultRules(),
+				lint.WithRule("house.checkData", checkData),
+				lint.WithRule("house.fail", lint.RuleFunc(func(ctx lint.Context, _ *lint.Package) {
+					ctx.ReportError(errors.New("crashed"))
+				})),
+			},
+			want:    []string{"house.checkData error 0 data.key: data is not allowed"},
+			wantErr: "rule 'house.fail' failed to run: crashed",
+		},
+		"InvalidPath": {
+			reason:    "Issues with paths that cannot be parsed fail the run.",
+			manifests: []string{testConfigMap},
+			opts: []lint.Option{lint.WithoutDefaultRules(), lint.WithRule("house.invalid", lint.RuleFunc(func(ctx lint.Context, pkg *lint.Package) {
+				ctx.ReportIssue(lint.Issue{Entry: &pkg.Entries[0], Path: "data[key", Description: "invalid"})
+			}))},
+			want:    []string{},
+			wantErr: "rule 'house.invalid' failed to run: cannot parse path 'data[key': ",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pkg := parseTestPackage(t, tc.manifests...)
+			report, err := lint.Lint(context.Background(), pkg, tc.opts...)
+
+			got := formatIssues(pkg, report.Issues)
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("\n%s\nLint(...): want issues %q, got %q", tc.reason, tc.want, got)
+			}
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+				runErr := &lint.RunError{}
+				if !errors.As(err, &runErr) {
+					t.Errorf("\n%s\nLint(...): want *lint.RunError, got %T", tc.reason, err)
+				}
+			}
+			if !strings.HasPrefix(gotErr, tc.wantErr) || (tc.wantErr == "") != (gotErr == "") {
+				t.Errorf("\n%s\nLint(...): want error %q, got %q", tc.reason, tc.wantErr, gotErr)
+			}
+		})
+	}
+}
+
+func TestSchemaStore(t *testing.T) {
+	pkg := parseTestPackage(t, testXRD)
+	store := lint.NewSchemaStore()
+	store.RegisterBundledKubernetesSchemas()
+	if skipped := store.RegisterPackage(pkg); len(skipped) != 0 {
+		t.Fatalf("RegisterPackage(...): unexpected skipped definitions: %v", skipped)
+	}
+
+	// The schema of XExample is found in the store, but
+	// composition.checkCompositeType requires the XRD in the linted package.
+	composition := parseTestPackage(t, testComposition)
+	report, err := lint.Lint(context.Background(), composition, lint.WithSchemaStore(store))
+	if err != nil {
+		t.Fatalf("Lint(...): unexpected error: %v", err)
+	}
+	want := []string{
+		"composition.checkCompositeType error 0 : no composite type found for XExample.example.org/v1",
+		"composition.checkPathFieldPaths error 0 spec.resources[0].patches[0].fromFieldPath: property 'regoin' not found, did you mean 'region'?",
+	}
+	if got := formatIssues(composition, report.Issues); !reflect.DeepEqual(want, got) {
+		t.Errorf("Lint(...): want issues %q, got %q", want, got)
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		fixes   []lint.Fix
+		want    string
+		wantErr bool
+	}{
+		"SetValue": {
+			reason: "Scalars are replaced at paths in field path notation.",
+			fixes:  []lint.Fix{{Type: lint.FixTypeSetValue, Path: "data.key", Value: "other"}},
+			want:   strings.Replace(testConfigMap, "key: value", "key: other", 1),
+		},
+		"AddField": {
+			reason: "Fields are added to the mapping at the path.",
+			fixes:  []lint.Fix{{Type: lint.FixTypeAddField, Path: "data", Key: "other", Value: "value"}},
+			want:   testConfigMap + "  other: value\n",
+		},
+		"InvalidPath": {
+			reason:  "Fixes with paths that cannot be parsed are rejected.",
+			fixes:   []lint.Fix{{Type: lint.FixTypeSetValue, Path: "data[key", Value: "other"}},
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := lint.ApplyFixes([]byte(testConfigMap), tc.fixes)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\nApplyFixes(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("\n%s\nApplyFixes(...): want %q, got %q", tc.reason, tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestTextPrinter(t *testing.T) {
+	pkg := parseTestPackage(t, testConfigMap)
+	report := lint.Report{Issues: []lint.Issue{{
+		RuleName:    "house.checkData",
+		Severity:    lint.SeverityWarning,
+		Entry:       &pkg.Entries[0],
+		Path:        "data.key",
+		Description: "data is not allowed",
+	}}}
+
+	out := &bytes.Buffer{}
+	if err := lint.NewTextPrinter(out).PrintReport(report); err != nil {
+		t.Fatalf("PrintReport(...): unexpected error: %v", err)
+	}
+	for _, want := range []string{"0.yaml", "data is not allowed", "house.checkData"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("PrintReport(...): want output to contain %q, got %q", want, out.String())
+		}
+	}
+}
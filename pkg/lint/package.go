@@ -0,0 +1,88 @@
+package lint
+
+import (
+	"github.com/spf13/afero"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/fetch"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/parse"
+)
+
+// Package is the content of a Crossplane package.
+type Package struct {
+	// Source that was used to create this package, for example a directory or
+	// a package image.
+	Source string
+
+	// Name of this package.
+	Name string
+
+	// Entries of this package. Issues point to the elements of Entries, so it
+	// must not be modified while the package is linted.
+	Entries []PackageEntry
+}
+
+// PackageEntry is a single object of a Package.
+type PackageEntry struct {
+	// Source of this entry, for example the path of its file.
+	Source string
+
+	// Raw manifest of this entry.
+	Raw string
+
+	// Object is the manifest decoded as JSON object.
+	Object map[string]interface{}
+}
+
+// ParseDirectory parses the YAML files in directory as a Package.
+func ParseDirectory(fs afero.Fs, directory string) (*Package, error) {
+	pkg, err := parse.NewPackageDirectoryParser(fs).ParsePackage(directory)
+	if err != nil {
+		return nil, err
+	}
+	return newPackage(pkg), nil
+}
+
+// ParseFile parses the YAML file at path as a PackageEntry.
+func ParseFile(fs afero.Fs, path string) (PackageEntry, error) {
+	e, err := parse.NewPackageDirectoryParser(fs).ParseFile(path)
+	if err != nil {
+		return PackageEntry{}, err
+	}
+	return newPackageEntry(&e), nil
+}
+
+// ParseImage fetches the Crossplane package image, for example
+// crossplanecontrib/provider-aws:v0.34.0, and parses its content. Fetched
+// images are cached in cacheFs if it is not nil.
+func ParseImage(image string, cacheFs afero.Fs) (*Package, error) {
+	var fetcher fetch.Fetcher = fetch.NewRemoteFetcher()
+	if cacheFs != nil {
+		fetcher = fetch.NewFsCacheFetcher(cacheFs, fetcher)
+	}
+	pkg, err := parse.NewPackageImageParser(fetcher).ParsePackage(image)
+	if err != nil {
+		return nil, err
+	}
+	return newPackage(pkg), nil
+}
+
+func newPackage(pkg *xpkg.Package) *Package {
+	p := &Package{
+		Source:  pkg.Source,
+		Name:    pkg.Name,
+		Entries: make([]PackageEntry, len(pkg.Entries)),
+	}
+	for i := range pkg.Entries {
+		p.Entries[i] = newPackageEntry(&pkg.Entries[i])
+	}
+	return p
+}
+
+func newPackageEntry(e *xpkg.PackageEntry) PackageEntry {
+	return PackageEntry{
+		Source: e.Source,
+		Raw:    e.Raw,
+		Object: e.Object.Object,
+	}
+}
@@ -0,0 +1,37 @@
+package lint
+
+import (
+	"io"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/print"
+)
+
+// Printer prints a Report.
+type Printer interface {
+	PrintReport(report Report) error
+}
+
+// NewTextPrinter creates a Printer that writes human readable output to out.
+func NewTextPrinter(out io.Writer) Printer {
+	return printer{printer: print.NewTextPrinter(out)}
+}
+
+// NewCodeFramePrinter creates a Printer that groups issues by file and shows
+// the lines of the manifest around each issue. Colors are only used if out is
+// a terminal and NO_COLOR is not set.
+func NewCodeFramePrinter(out io.Writer) Printer {
+	return printer{printer: print.NewCodeFramePrinter(out)}
+}
+
+// printer prints a Report with a printer of the linter.
+type printer struct {
+	printer print.Printer
+}
+
+func (p printer) PrintReport(report Report) error {
+	r, err := newConverter().internalReport(report)
+	if err != nil {
+		return err
+	}
+	return p.printer.PrintReport(r)
+}
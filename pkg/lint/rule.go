@@ -0,0 +1,273 @@
+package lint
+
+import (
+	"context"
+	"time"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/config"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg"
+	internallint "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint"
+	linter "github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter"
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/linter/rules"
+)
+
+// Rule checks a package for issues.
+type Rule interface {
+	// Validate pkg and report the discovered issues to ctx.
+	Validate(ctx Context, pkg *Package)
+}
+
+// RuleFunc is a function that implements Rule.
+type RuleFunc func(ctx Context, pkg *Package)
+
+// Validate calls f.
+func (f RuleFunc) Validate(ctx Context, pkg *Package) {
+	f(ctx, pkg)
+}
+
+// Context is passed to rules to look up schemas and report issues. It is only
+// implemented by the linter, so methods may be added in minor releases.
+type Context interface {
+	// Context of the linter run. Rules should stop when it is done.
+	Context() context.Context
+
+	// ReportIssue reports issue. The name and default severity of the rule
+	// are used if issue.RuleName or issue.Severity are empty. issue.Entry
+	// must point to an element of the Entries of the validated Package.
+	ReportIssue(issue Issue)
+
+	// ReportError reports that the rule failed to run, for example because
+	// an external command crashed. Lint returns a *RunError in this case.
+	ReportError(err error)
+
+	// GetSchema returns the OpenAPI schema of a kind or nil if the kind is
+	// unknown.
+	GetSchema(apiVersion, kind string) *extv1.JSONSchemaProps
+
+	// GetSchemaOrigin returns where the schema of a kind is defined or nil if
+	// the kind is unknown.
+	GetSchemaOrigin(apiVersion, kind string) *SchemaOrigin
+
+	// HasFunction determines if the composition function name is installed.
+	HasFunction(name string) bool
+
+	// GetEnvironmentSchema returns the schema of the environment of
+	// compositions or nil if it is not configured.
+	GetEnvironmentSchema() *extv1.JSONSchemaProps
+}
+
+// Targets of custom rules.
+const (
+	// CustomRuleTargetObject matches the objects of a package.
+	CustomRuleTargetObject = config.CustomRuleTargetObject
+
+	// CustomRuleTargetComposedBase matches the bases of composed resources.
+	CustomRuleTargetComposedBase = config.CustomRuleTargetComposedBase
+)
+
+// CustomRuleSpec defines a rule as CEL expression. See NewCustomRule.
+type CustomRuleSpec struct {
+	// Name of the rule.
+	Name string
+
+	// Severity of the reported issues. Defaults to SeverityError.
+	Severity Severity
+
+	// Match selects the objects the rule is evaluated for.
+	Match CustomRuleMatch
+
+	// Expression that must evaluate to true for valid objects. The object is
+	// available as variable object. Rules for composed bases can access the
+	// composed resource template as resource and the Composition as
+	// composition.
+	Expression string
+
+	// Message of the reported issues.
+	Message string
+
+	// Path of the reported issues relative to the object, for example
+	// spec.deletionPolicy.
+	Path string
+}
+
+// CustomRuleMatch selects objects by target and kind.
+type CustomRuleMatch struct {
+	// Target of the rule. Either CustomRuleTargetObject or
+	// CustomRuleTargetComposedBase. Defaults to CustomRuleTargetObject.
+	Target string
+
+	// APIVersion of matching objects. Matches any if empty.
+	APIVersion string
+
+	// Kind of matching objects. Matches any if empty.
+	Kind string
+}
+
+// PluginSpec defines an executable that receives the package on stdin and
+// writes the discovered issues to stdout. See NewPluginRule.
+type PluginSpec struct {
+	// Name of the plugin. Defaults to the file name of Command.
+	Name string
+
+	// Command is the path of the executable.
+	Command string
+
+	// Args passed to Command.
+	Args []string
+
+	// Timeout of a single plugin run. Defaults to 30s.
+	Timeout time.Duration
+}
+
+// NewCustomRule creates a Rule that evaluates a CEL expression.
+func NewCustomRule(spec CustomRuleSpec) (Rule, error) {
+	rule, err := rules.NewCustomRule(config.CustomRule{
+		Name:     spec.Name,
+		Severity: string(spec.Severity),
+		Match: config.CustomRuleMatch{
+			Target:     spec.Match.Target,
+			APIVersion: spec.Match.APIVersion,
+			Kind:       spec.Match.Kind,
+		},
+		Expression: spec.Expression,
+		Message:    spec.Message,
+		Path:       spec.Path,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return internalRule{rule: linter.LinterRuleFunc(rule.Check)}, nil
+}
+
+// NewPluginRule creates a Rule that runs an external plugin.
+func NewPluginRule(spec PluginSpec) (Rule, error) {
+	plugin := config.Plugin{
+		Name:    spec.Name,
+		Command: spec.Command,
+		Args:    spec.Args,
+	}
+	if spec.Timeout != 0 {
+		plugin.Timeout = spec.Timeout.String()
+	}
+	rule, err := rules.NewPluginRule(plugin)
+	if err != nil {
+		return nil, err
+	}
+	return internalRule{rule: linter.LinterRuleFunc(rule.Check)}, nil
+}
+
+// ruleAdapter runs a Rule of this package as rule of the linter.
+type ruleAdapter struct {
+	rule Rule
+	conv *converter
+	pkg  *Package
+}
+
+func (r ruleAdapter) Validate(ctx internallint.LinterContext, _ *xpkg.Package) {
+	r.rule.Validate(&ruleContext{ctx: ctx, conv: r.conv}, r.pkg)
+}
+
+// ruleContext is the Context of a Rule run by the linter.
+type ruleContext struct {
+	ctx  internallint.LinterContext
+	conv *converter
+}
+
+func (c *ruleContext) Context() context.Context {
+	return c.ctx.Context()
+}
+
+func (c *ruleContext) ReportIssue(issue Issue) {
+	iss, err := c.conv.internalIssue(issue)
+	if err != nil {
+		c.ctx.ReportError(err)
+		return
+	}
+	c.ctx.ReportIssue(iss)
+}
+
+func (c *ruleContext) ReportError(err error) {
+	c.ctx.ReportError(err)
+}
+
+func (c *ruleContext) GetSchema(apiVersion, kind string) *extv1.JSONSchemaProps {
+	crd := c.ctx.GetCRDSchema(schema.FromAPIVersionAndKind(apiVersion, kind))
+	if crd == nil || crd.Schema == nil {
+		return nil
+	}
+	return crd.Schema.OpenAPIV3Schema
+}
+
+func (c *ruleContext) GetSchemaOrigin(apiVersion, kind string) *SchemaOrigin {
+	origins, ok := c.ctx.(internallint.SchemaOrigins)
+	if !ok {
+		return nil
+	}
+	origin := origins.GetSchemaOrigin(schema.FromAPIVersionAndKind(apiVersion, kind))
+	if origin == nil {
+		return nil
+	}
+	o := publicOrigin(*origin)
+	return &o
+}
+
+func (c *ruleContext) HasFunction(name string) bool {
+	return c.ctx.HasFunction(name)
+}
+
+func (c *ruleContext) GetEnvironmentSchema() *extv1.JSONSchemaProps {
+	return c.ctx.GetEnvironmentSchema()
+}
+
+// internalRule is a rule of the linter returned as Rule. Lint runs it
+// directly, so only rules that are called outside of Lint are adapted.
+type internalRule struct {
+	rule linter.LinterRule
+}
+
+func (r internalRule) Validate(ctx Context, pkg *Package) {
+	conv := newConverter()
+	r.rule.Validate(&internalContext{ctx: ctx, conv: conv}, conv.internalPackage(pkg))
+}
+
+// internalContext is the context of a rule of the linter that is run with a
+// Context of this package.
+type internalContext struct {
+	ctx  Context
+	conv *converter
+}
+
+func (c *internalContext) Context() context.Context {
+	return c.ctx.Context()
+}
+
+func (c *internalContext) ReportIssue(issue internallint.Issue) {
+	c.ctx.ReportIssue(c.conv.publicIssue(issue))
+}
+
+func (c *internalContext) ReportError(err error) {
+	c.ctx.ReportError(err)
+}
+
+func (c *internalContext) GetCRDSchema(gvk schema.GroupVersionKind) *extv1.CustomResourceDefinitionVersion {
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+	props := c.ctx.GetSchema(apiVersion, kind)
+	if props == nil {
+		return nil
+	}
+	return &extv1.CustomResourceDefinitionVersion{
+		Name:   gvk.Version,
+		Schema: &extv1.CustomResourceValidation{OpenAPIV3Schema: props},
+	}
+}
+
+func (c *internalContext) HasFunction(name string) bool {
+	return c.ctx.HasFunction(name)
+}
+
+func (c *internalContext) GetEnvironmentSchema() *extv1.JSONSchemaProps {
+	return c.ctx.GetEnvironmentSchema()
+}
@@ -0,0 +1,152 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/crossplane-contrib/crossplane-lint/internal/xpkg/lint/schema"
+)
+
+// BundledKubernetesVersion is the version of Kubernetes whose API schemas are
+// bundled with the linter.
+const BundledKubernetesVersion = schema.BundledKubernetesVersion
+
+const (
+	errFmtSkippedDefinition = "skipped schemas of %s in %s: %v"
+)
+
+// SchemaStore holds the schemas of the kinds known to the linter. It is safe
+// to lint with a SchemaStore concurrently, but not to register schemas while
+// it is in use.
+type SchemaStore struct {
+	store *schema.SchemaStore
+	conv  *converter
+}
+
+// NewSchemaStore creates a new empty SchemaStore. Use RegisterPackage to
+// register the schemas of a package and RegisterBundledKubernetesSchemas or
+// RegisterOpenAPISchemas to register the built-in Kubernetes kinds.
+func NewSchemaStore() *SchemaStore {
+	return &SchemaStore{
+		store: schema.NewSchemaStore(),
+		conv:  newConverter(),
+	}
+}
+
+// SkippedDefinition is a CRD or XRD whose schemas could not be registered.
+type SkippedDefinition struct {
+	// Entry that defines the schemas.
+	Entry *PackageEntry
+
+	// Err is the reason the schemas could not be registered.
+	Err error
+}
+
+func (d SkippedDefinition) Error() string {
+	return fmt.Sprintf(errFmtSkippedDefinition, d.name(), d.Entry.Source, d.Err)
+}
+
+func (d SkippedDefinition) Unwrap() error {
+	return d.Err
+}
+
+func (d SkippedDefinition) name() string {
+	metadata, _ := d.Entry.Object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
+
+// RegisterPackage registers the CRDs, XRDs and Functions of pkg. A kind that
+// is already defined by another manifest keeps its schema, so the first
+// registered package wins. Manifests that are registered again replace their
+// previous schemas. CRDs and XRDs that cannot be converted are skipped and
+// returned.
+func (s *SchemaStore) RegisterPackage(pkg *Package) []SkippedDefinition {
+	return s.skipped(s.store.RegisterPackage(s.conv.internalPackage(pkg)))
+}
+
+// UpdatePackage registers the schemas of all registered packages again after
+// the entries of pkg changed. Schemas of removed or changed manifests are
+// unregistered and definitions they shadowed take effect again. pkg is
+// registered if it was not registered before. Returns the skipped definitions
+// of pkg.
+func (s *SchemaStore) UpdatePackage(pkg *Package) []SkippedDefinition {
+	return s.skipped(s.store.UpdatePackage(s.conv.internalPackage(pkg)))
+}
+
+func (s *SchemaStore) skipped(skipped []schema.SkippedDefinition) []SkippedDefinition {
+	var defs []SkippedDefinition
+	for _, d := range skipped {
+		defs = append(defs, SkippedDefinition{Entry: s.conv.publicEntry(d.Entry), Err: d.Err})
+	}
+	return defs
+}
+
+// RegisterBundledKubernetesSchemas registers the schemas of the built-in
+// Kubernetes kinds of BundledKubernetesVersion.
+func (s *SchemaStore) RegisterBundledKubernetesSchemas() {
+	s.store.RegisterBundledKubernetesSchemas()
+}
+
+// RegisterOpenAPISchemas registers the schemas of the Kubernetes kinds defined
+// by the OpenAPI v3 or v2 document raw, for example the output of
+// `kubectl get --raw /openapi/v3/api/v1`. Returns the number of registered
+// kinds.
+func (s *SchemaStore) RegisterOpenAPISchemas(raw []byte, source string) (int, error) {
+	return s.store.RegisterOpenAPISchemas(raw, source)
+}
+
+// GetSchemaConflicts returns the kinds that are defined more than once with
+// different schemas.
+func (s *SchemaStore) GetSchemaConflicts() []SchemaConflict {
+	var conflicts []SchemaConflict
+	for _, c := range s.store.GetSchemaConflicts() {
+		apiVersion, kind := c.GVK.ToAPIVersionAndKind()
+		conflicts = append(conflicts, SchemaConflict{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Used:       publicOrigin(c.Used),
+			Ignored:    publicOrigin(c.Ignored),
+		})
+	}
+	return conflicts
+}
+
+// SchemaOrigin describes where the schema of a kind is defined.
+type SchemaOrigin struct {
+	// Package that defines the schema, for example a package image or a
+	// directory.
+	Package string
+
+	// Source of the manifest that defines the schema. Empty if the schema is
+	// not defined by a manifest, for example for the bundled Kubernetes
+	// schemas.
+	Source string
+
+	// Kind of the definition, for example CRD or XRD.
+	Kind string
+
+	// Name of the definition.
+	Name string
+}
+
+// String returns a description of o, for example
+// "crossplanecontrib/provider-aws:v0.34.0 CRD rdsinstances.database.aws.crossplane.io".
+func (o SchemaOrigin) String() string {
+	if o.Package == "" {
+		return fmt.Sprintf("%s %s", o.Kind, o.Name)
+	}
+	return fmt.Sprintf("%s %s %s", o.Package, o.Kind, o.Name)
+}
+
+// SchemaConflict is a kind that is defined more than once with different
+// schemas.
+type SchemaConflict struct {
+	APIVersion string
+	Kind       string
+
+	// Used is the definition the kind is validated against.
+	Used SchemaOrigin
+
+	// Ignored is the conflicting definition.
+	Ignored SchemaOrigin
+}